@@ -0,0 +1,457 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+)
+
+// Options collected from the command line
+type options struct {
+	bytes           bool
+	lines           bool
+	characters      bool
+	words           bool
+	maxLineLength   bool
+	invalid         bool
+	graphemes       bool
+	match           *regexp.Regexp
+	invert          bool
+	wordMode        utils.WordMode
+	encoding        utils.Encoding
+	compression     utils.Compression
+	compressedBytes bool
+	output          string
+	jobs            int
+	recursive       bool
+	include         []string
+	exclude         []string
+	gitignore       bool
+	skipBinary      bool
+	filesFrom       string
+	filesFromSep    byte
+	top             int
+	foldCase        bool
+	stopWords       []string
+	histogram       bool
+	bucket          int64
+	sloc            bool
+	readability     bool
+	content         utils.ContentMode
+	byteRange       utils.Range
+	stat            bool
+	rev             string
+	diff            string
+	follow          bool
+	interval        time.Duration
+	interactive     bool
+	help            bool
+	files           []string
+}
+
+// countOptions returns how the counters read the characters of every input
+func (opts options) countOptions() utils.CountOptions {
+	return utils.CountOptions{
+		Encoding:  opts.encoding,
+		Words:     opts.wordMode,
+		Graphemes: opts.graphemes,
+		// the characters only have to be decoded for the other counters & reports
+		LinesOnly: !opts.characters && !opts.words && !opts.maxLineLength && !opts.invalid && !opts.graphemes &&
+			opts.top == 0 && !opts.histogram && opts.match == nil && !opts.sloc && opts.content == "" && !opts.readability,
+
+		Compression:     opts.compression,
+		CompressedBytes: opts.compressedBytes,
+
+		WordFrequencies: opts.top > 0,
+		FoldCase:        opts.foldCase,
+		StopWords:       opts.stopWords,
+		LineHistogram:   opts.histogram,
+
+		Match:  opts.match,
+		Invert: opts.invert,
+
+		SLOC:    opts.sloc,
+		Content: opts.content,
+
+		Readability: opts.readability,
+
+		Range: opts.byteRange,
+	}
+}
+
+// distributions reports whether anything is written after the counts, see reporter
+func (opts options) distributions() bool {
+	return opts.top > 0 || opts.histogram || opts.sloc
+}
+
+// Throwing the custom error while parsing flags
+var ErrUsage = errors.New("invalid usage")
+
+// printParseError writes an error of parseArgs like GNU wc does, pointing to
+// --help after a usage error rather than writing the whole help
+func printParseError(w io.Writer, err error) {
+	fmt.Fprintf(w, "wc: %v\n", err)
+	if errors.Is(err, ErrUsage) {
+		fmt.Fprintln(w, "Try 'wc --help' for more information.")
+	}
+}
+
+// Long options taking a value, as --name VALUE or --name=VALUE
+var valueOptions = map[string]bool{
+	"filename": true,
+	"output":   true,
+	"jobs":     true,
+	"encoding": true,
+	"words-as": true,
+	"match":    true,
+	"content":  true,
+
+	"compression": true,
+	"include":     true,
+	"exclude":     true,
+
+	"interval": true,
+
+	"rev":  true,
+	"diff": true,
+
+	"offset":    true,
+	"length":    true,
+	"from-line": true,
+	"to-line":   true,
+
+	"top":        true,
+	"stop-words": true,
+	"bucket":     true,
+
+	"files0-from": true,
+	"files-from":  true,
+}
+
+// Short options taking a value, as -f VALUE or -fVALUE
+var shortValueOptions = map[byte]string{
+	'f': "filename",
+	'j': "jobs",
+}
+
+// set stores the value of an option listed in valueOptions
+func (opts *options) set(name string, value string) error {
+	switch name {
+	case "filename":
+		opts.files = append(opts.files, value)
+	case "output":
+		opts.output = value
+	case "jobs":
+		jobs, err := strconv.Atoi(value)
+		if err != nil || jobs < 0 {
+			return fmt.Errorf("%w: invalid number of jobs: '%s'", ErrUsage, value)
+		}
+		opts.jobs = jobs
+	case "encoding":
+		encoding, err := utils.ParseEncoding(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUsage, err)
+		}
+		opts.encoding = encoding
+	case "match":
+		match, err := regexp.Compile(value)
+		if err != nil {
+			return fmt.Errorf("%w: invalid pattern: %v", ErrUsage, err)
+		}
+		opts.match = match
+	case "content":
+		content, err := utils.ParseContentMode(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUsage, err)
+		}
+		opts.content = content
+	case "compression":
+		compression, err := utils.ParseCompression(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUsage, err)
+		}
+		opts.compression = compression
+	case "words-as":
+		wordMode, err := utils.ParseWordMode(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUsage, err)
+		}
+		opts.wordMode = wordMode
+	case "include":
+		opts.include = append(opts.include, value)
+	case "exclude":
+		opts.exclude = append(opts.exclude, value)
+	case "top":
+		top, err := strconv.Atoi(value)
+		if err != nil || top <= 0 {
+			return fmt.Errorf("%w: invalid number of words: '%s'", ErrUsage, value)
+		}
+		opts.top = top
+	case "stop-words":
+		stopWords, err := readStopWords(value)
+		if err != nil {
+			return err
+		}
+		opts.stopWords = append(opts.stopWords, stopWords...)
+	case "bucket":
+		bucket, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || bucket <= 0 {
+			return fmt.Errorf("%w: invalid bucket size: '%s'", ErrUsage, value)
+		}
+		opts.bucket = bucket
+	case "interval":
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("%w: invalid interval: '%s'", ErrUsage, value)
+		}
+		opts.interval = interval
+	case "offset", "length", "from-line", "to-line":
+		number, err := strconv.ParseInt(value, 10, 64)
+		// only the offset can be 0, a length of 0 would select nothing
+		if err != nil || number < 0 || number == 0 && name != "offset" {
+			return fmt.Errorf("%w: invalid %s: '%s'", ErrUsage, strings.ReplaceAll(name, "-", " "), value)
+		}
+		switch name {
+		case "offset":
+			opts.byteRange.Offset = number
+		case "length":
+			opts.byteRange.Length = number
+		case "from-line":
+			opts.byteRange.FromLine = number
+		case "to-line":
+			opts.byteRange.ToLine = number
+		}
+	case "rev":
+		opts.rev = value
+	case "diff":
+		// only the two dot form names both sides of the change
+		from, _, _ := strings.Cut(value, "..")
+		if from == "" || strings.Contains(value, "...") {
+			return fmt.Errorf("%w: invalid revisions: '%s', expected REV or REV..REV", ErrUsage, value)
+		}
+		opts.diff = value
+	case "files0-from":
+		opts.filesFrom, opts.filesFromSep = value, 0
+	case "files-from":
+		opts.filesFrom, opts.filesFromSep = value, '\n'
+	}
+	return nil
+}
+
+// Default width of the --histogram buckets
+const DefaultBucket int64 = 10
+
+// readStopWords returns the built-in list for "english", or the words of the named file
+func readStopWords(name string) ([]string, error) {
+	if name == "english" {
+		return utils.EnglishStopWords, nil
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+	return utils.ReadStopWords(file)
+}
+
+// parseArgs parses `wc [-c] [-l] [-m] [-w] [file ...]` style arguments.
+// Short flags may be combined (-lw) and the long forms listed in Message are accepted.
+func parseArgs(args []string) (options, error) {
+	opts := options{jobs: 1, interval: DefaultInterval, bucket: DefaultBucket}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		// everything after "--" is a filename
+		case arg == "--":
+			opts.files = append(opts.files, args[i+1:]...)
+			i = len(args)
+
+		// long flags, optionally in the --name=value form
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			switch name {
+			case "bytes":
+				opts.bytes = true
+			case "lines":
+				opts.lines = true
+			case "characters", "chars":
+				opts.characters = true
+			case "words":
+				opts.words = true
+			case "max-line-length":
+				opts.maxLineLength = true
+			case "invalid":
+				opts.invalid = true
+			case "graphemes":
+				opts.graphemes = true
+			case "compressed-bytes":
+				opts.compressedBytes = true
+			case "recursive":
+				opts.recursive = true
+			case "gitignore":
+				opts.gitignore = true
+			case "skip-binary":
+				opts.skipBinary = true
+			case "invert":
+				opts.invert = true
+			case "fold-case":
+				opts.foldCase = true
+			case "histogram":
+				opts.histogram = true
+			case "readability":
+				opts.readability = true
+			case "sloc":
+				opts.sloc = true
+			case "stat":
+				opts.stat = true
+			case "follow":
+				opts.follow = true
+			case "interactive":
+				opts.interactive = true
+			case "help":
+				opts.help = true
+			default:
+				if !valueOptions[name] {
+					return opts, fmt.Errorf("%w: unrecognized option '%s'", ErrUsage, arg)
+				}
+				if !hasValue {
+					if i+1 >= len(args) {
+						return opts, fmt.Errorf("%w: option '--%s' requires an argument", ErrUsage, name)
+					}
+					i++
+					value = args[i]
+				}
+				if err := opts.set(name, value); err != nil {
+					return opts, err
+				}
+				continue
+			}
+			if hasValue {
+				return opts, fmt.Errorf("%w: option '--%s' doesn't allow an argument", ErrUsage, name)
+			}
+
+		// short flags, possibly combined like -lw
+		case len(arg) > 1 && arg[0] == '-':
+			for j := 1; j < len(arg); j++ {
+				switch arg[j] {
+				case 'c':
+					opts.bytes = true
+				case 'l':
+					opts.lines = true
+				case 'm':
+					opts.characters = true
+				case 'w':
+					opts.words = true
+				case 'L':
+					opts.maxLineLength = true
+				case 'r':
+					opts.recursive = true
+				case 'h':
+					opts.help = true
+				default:
+					name, ok := shortValueOptions[arg[j]]
+					if !ok {
+						return opts, fmt.Errorf("%w: invalid option -- '%c'", ErrUsage, arg[j])
+					}
+					// the value is either the rest of this argument or the next one
+					value := arg[j+1:]
+					if value == "" {
+						if i+1 >= len(args) {
+							return opts, fmt.Errorf("%w: option requires an argument -- '%c'", ErrUsage, arg[j])
+						}
+						i++
+						value = args[i]
+					}
+					if err := opts.set(name, value); err != nil {
+						return opts, err
+					}
+					j = len(arg)
+				}
+			}
+
+		// plain arguments are filenames
+		default:
+			opts.files = append(opts.files, arg)
+		}
+	}
+
+	// like GNU wc, names come either from the command line or from a list
+	if opts.filesFrom != "" && len(opts.files) > 0 {
+		return opts, fmt.Errorf("%w: extra operand '%s', file operands cannot be combined with a file list", ErrUsage, opts.files[0])
+	}
+
+	// only files on the command line can be followed, and only in the plain format
+	if opts.follow {
+		if len(opts.files) == 0 || opts.filesFrom != "" || opts.recursive {
+			return opts, fmt.Errorf("%w: --follow needs the files to follow on the command line", ErrUsage)
+		}
+		for _, filename := range opts.files {
+			if filename == Stdin {
+				return opts, fmt.Errorf("%w: the standard input can't be followed", ErrUsage)
+			}
+		}
+		if opts.output != "" && opts.output != OutputPlain {
+			return opts, fmt.Errorf("%w: --follow only writes the plain format", ErrUsage)
+		}
+	}
+
+	// only part of the file can't be followed as it grows
+	if opts.follow && !opts.byteRange.IsZero() {
+		return opts, fmt.Errorf("%w: --follow counts whole files, without --offset, --length, --from-line or --to-line", ErrUsage)
+	}
+	if opts.byteRange.ToLine > 0 && opts.byteRange.FromLine > opts.byteRange.ToLine {
+		return opts, fmt.Errorf("%w: --from-line %d is after --to-line %d", ErrUsage, opts.byteRange.FromLine, opts.byteRange.ToLine)
+	}
+	if opts.byteRange.FromLine > 0 || opts.byteRange.ToLine > 0 {
+		switch opts.encoding {
+		case utils.UTF16, utils.UTF16LE, utils.UTF16BE:
+			return opts, fmt.Errorf("%w: %v", ErrUsage, utils.ErrLineRangeEncoding)
+		}
+	}
+
+	// the files of --rev & --diff are the ones tracked by git below the paths given
+	if opts.rev != "" || opts.diff != "" {
+		if opts.rev != "" && opts.diff != "" {
+			return opts, fmt.Errorf("%w: --rev and --diff can't be combined", ErrUsage)
+		}
+		if opts.filesFrom != "" || opts.recursive || opts.stat || opts.follow {
+			return opts, fmt.Errorf("%w: --rev and --diff can't be combined with a file list, -r, --stat or --follow", ErrUsage)
+		}
+		for _, filename := range opts.files {
+			if filename == Stdin {
+				return opts, fmt.Errorf("%w: the standard input has no revisions", ErrUsage)
+			}
+		}
+	}
+
+	// the longest line, the scores & the distributions can't be told apart into changes
+	if opts.diff != "" && (opts.maxLineLength || opts.readability || opts.distributions()) {
+		return opts, fmt.Errorf("%w: -L, --readability, --top, --histogram and --sloc aren't available with --diff", ErrUsage)
+	}
+
+	if opts.invert && opts.match == nil {
+		return opts, fmt.Errorf("%w: --invert needs a pattern given with --match", ErrUsage)
+	}
+
+	// the csv format has a single record per input
+	if opts.distributions() && opts.output == OutputCSV {
+		return opts, fmt.Errorf("%w: --top, --histogram and --sloc aren't available in the csv format", ErrUsage)
+	}
+
+	// GNU wc prints lines, words and bytes when no counter is selected,
+	// with a pattern, --sloc or --content only their counts are written
+	if opts.match == nil && !opts.sloc && opts.content == "" && !opts.bytes && !opts.lines && !opts.characters && !opts.words && !opts.maxLineLength && !opts.invalid && !opts.graphemes {
+		opts.lines, opts.words, opts.bytes = true, true, true
+	}
+
+	return opts, nil
+}
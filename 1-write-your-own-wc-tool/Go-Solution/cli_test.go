@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		want  options
+		files []string
+	}{
+		{"default counters", []string{"a.txt"}, options{lines: true, words: true, bytes: true}, []string{"a.txt"}},
+		{"combined short flags", []string{"-lw", "a.txt"}, options{lines: true, words: true}, []string{"a.txt"}},
+		{"separate short flags", []string{"-c", "-m", "a.txt"}, options{bytes: true, characters: true}, []string{"a.txt"}},
+		{"short flag with its value", []string{"-j4", "-l"}, options{lines: true, jobs: 4}, nil},
+		{"short flag with the next value", []string{"-lj", "4"}, options{lines: true, jobs: 4}, nil},
+		{"long flag", []string{"--max-line-length"}, options{maxLineLength: true}, nil},
+		{"long flag with =value", []string{"--output=json", "--jobs=2", "-l"}, options{lines: true, output: "json", jobs: 2}, nil},
+		{"long flag with the next value", []string{"--output", "csv", "-l"}, options{lines: true, output: "csv"}, nil},
+		{"filename option", []string{"--filename", "a.txt", "-f", "b.txt", "-l"}, options{lines: true}, []string{"a.txt", "b.txt"}},
+		{"double dash", []string{"-l", "--", "-w", "--bytes"}, options{lines: true}, []string{"-w", "--bytes"}},
+		{"lone dash", []string{"-l", "-"}, options{lines: true}, []string{Stdin}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseArgs(test.args)
+			if err != nil {
+				t.Fatalf("parseArgs(%q): %v", test.args, err)
+			}
+			if !reflect.DeepEqual(got.files, test.files) {
+				t.Errorf("files = %q, want %q", got.files, test.files)
+			}
+
+			// only compare the fields set by the test, the rest keep their defaults
+			want := test.want
+			if want.jobs == 0 {
+				want.jobs = 1
+			}
+			selected := []struct {
+				name      string
+				got, want any
+			}{
+				{"lines", got.lines, want.lines},
+				{"words", got.words, want.words},
+				{"bytes", got.bytes, want.bytes},
+				{"characters", got.characters, want.characters},
+				{"maxLineLength", got.maxLineLength, want.maxLineLength},
+				{"jobs", got.jobs, want.jobs},
+				{"output", got.output, want.output},
+			}
+			for _, field := range selected {
+				if field.got != field.want {
+					t.Errorf("%s = %v, want %v", field.name, field.got, field.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseArgsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown short flag", []string{"-x"}},
+		{"unknown long flag", []string{"--nope"}},
+		{"missing value", []string{"--output"}},
+		{"missing short value", []string{"-j"}},
+		{"value on a flag", []string{"--lines=yes"}},
+		{"invalid number", []string{"--jobs", "many"}},
+		{"invert without match", []string{"--invert"}},
+		{"zero length", []string{"--length", "0"}},
+		{"zero line", []string{"--from-line=0"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := parseArgs(test.args); !errors.Is(err, ErrUsage) {
+				t.Errorf("parseArgs(%q) = %v, want an error wrapping ErrUsage", test.args, err)
+			}
+		})
+	}
+}
+
+func TestPrintParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"usage error", fmt.Errorf("%w: unrecognized option '--nope'", ErrUsage), "wc: invalid usage: unrecognized option '--nope'\nTry 'wc --help' for more information.\n"},
+		{"other error", errors.New("error opening stop words: no such file"), "wc: error opening stop words: no such file\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var w strings.Builder
+			printParseError(&w, test.err)
+			if w.String() != test.want {
+				t.Errorf("printParseError = %q, want %q", w.String(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+)
+
+// Default time between two updates of --follow
+const DefaultInterval time.Duration = time.Second
+
+// follow counts the files as they grow and writes their counts every
+// interval, with the number of lines appended per second, until interrupted
+func follow(opts options) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	followers := make([]*utils.Follower, 0, len(opts.files))
+	for _, filename := range opts.files {
+		// appended bytes can't be decompressed on their own
+		if isDecompressed(filename, opts) {
+			fmt.Fprintf(os.Stderr, "wc: %s: a compressed file can't be followed, --compression none counts its bytes as they are\n", filename)
+			return 1
+		}
+		follower, err := utils.NewFollower(filename, opts.countOptions())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wc: %s: %s\n", filename, errorMessage(err))
+			return 1
+		}
+		defer follower.Close()
+		followers = append(followers, follower)
+	}
+
+	// the counts only grow, leave room for them
+	width := max(numberWidth(opts.files, opts), 7)
+	previous := make([]utils.Counts, len(followers))
+	var last time.Time
+
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	for {
+		// there is no rate before the second update
+		now := time.Now()
+		elapsed := 0.0
+		if !last.IsZero() {
+			elapsed = now.Sub(last).Seconds()
+		}
+		last = now
+
+		var total utils.Counts
+		var totalRate float64
+		for i, follower := range followers {
+			filename := opts.files[i]
+			counts, reset, err := follower.Poll()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "wc: %s: %s\n", filename, errorMessage(err))
+			}
+			if reset {
+				fmt.Fprintf(os.Stderr, "wc: %s: file truncated or replaced, counting from its start\n", filename)
+				previous[i] = utils.Counts{}
+			}
+
+			rate := linesPerSecond(previous[i], counts, elapsed)
+			printFollowed(os.Stdout, counts, filename, rate, opts, width)
+			previous[i] = counts
+			total.Add(counts)
+			totalRate += rate
+		}
+		if len(followers) > 1 {
+			printFollowed(os.Stdout, total, "total", totalRate, opts, width)
+		}
+
+		// the counts are written one last time once interrupted
+		if ctx.Err() != nil {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// linesPerSecond returns the rate lines were appended at between two polls, 0 for the first one
+func linesPerSecond(previous utils.Counts, counts utils.Counts, elapsed float64) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(counts.Lines-previous.Lines) / elapsed
+}
+
+// printFollowed writes a row of counts followed by the rate of appended lines
+func printFollowed(w io.Writer, counts utils.Counts, name string, rate float64, opts options, width int) {
+	printCounts(w, counts, fmt.Sprintf("%s %.1f lines/s", name, rate), opts, width)
+}
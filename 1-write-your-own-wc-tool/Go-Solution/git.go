@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/wordcount"
+)
+
+// countRevision computes the counts of the named file as it is at the git revision rev
+func countRevision(ctx context.Context, rev string, filename string, options utils.CountOptions) (utils.Counts, error) {
+	file, err := utils.OpenGitFile(ctx, rev, filename)
+	if err != nil {
+		return utils.Counts{}, err
+	}
+
+	// make sure to stop git upon return
+	defer file.Close()
+
+	return wordcount.Count(ctx, file, wordcount.Options{CountOptions: fileOptions(filename, options)})
+}
+
+// diff writes how the counts of every file changed between the two revisions
+// of --diff, or between its revision & the working tree, with the change of
+// their total. Files which can't be counted are reported on stderr and
+// skipped; the returned exit status is 1 if any failed.
+func diff(opts options) int {
+	// like git, A.. is A..HEAD, only a single revision is compared to the working tree
+	from, to, found := strings.Cut(opts.diff, "..")
+	if found && to == "" {
+		to = "HEAD"
+	}
+
+	// an interrupt stops counting, the files not counted yet are reported as errors
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	changes, err := utils.GitChanges(ctx, from, to, opts.files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wc: %v\n", err)
+		return 1
+	}
+	if len(changes) == 0 {
+		return 0
+	}
+
+	files := make([]string, len(changes))
+	statuses := make(map[string]utils.GitStatus, len(changes))
+	for i, change := range changes {
+		files[i] = change.Path
+		statuses[change.Path] = change.Status
+	}
+	opts.files = files
+
+	output, err := newReporter(os.Stdout, files, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wc: %v\n", err)
+		return 1
+	}
+
+	// an added file is counted from nothing, a deleted one down to nothing
+	options := opts.countOptions()
+	count := func(ctx context.Context, filename string, chunks int) (utils.Counts, error) {
+		var before, after utils.Counts
+		var err error
+		if statuses[filename] != utils.GitAdded {
+			if before, err = countRevision(ctx, from, filename, options); err != nil {
+				return utils.Counts{}, err
+			}
+		}
+		if statuses[filename] != utils.GitDeleted {
+			if to == "" {
+				after, err = countFile(ctx, filename, chunks, options)
+			} else {
+				after, err = countRevision(ctx, to, filename, options)
+			}
+			if err != nil {
+				return utils.Counts{}, err
+			}
+		}
+		after.Subtract(before)
+		return after, nil
+	}
+
+	status := 0
+	var total utils.Counts
+	countFiles(ctx, files, opts.jobs, count, func(i int, filename string, counts utils.Counts, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "wc: %s: %s\n", filename, errorMessage(err))
+			status = 1
+		} else {
+			total.Add(counts)
+		}
+		output.counts(filename, counts, err)
+	})
+
+	if len(files) > 1 {
+		output.total(total)
+	}
+
+	if err := output.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "wc: %v\n", err)
+		status = 1
+	}
+
+	return status
+}
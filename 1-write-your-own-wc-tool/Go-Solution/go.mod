@@ -1,3 +1,10 @@
 module github.com/abhishekpatel946/1-write-your-own-wc-tool
 
-go 1.21.2
+go 1.22
+
+require (
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/text v0.14.0
+)
+
+require github.com/klauspost/compress v1.18.0
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 
 	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/wordcount"
 )
 
 // Configuration
 const Message string = `
+Usage: wc [OPTION]... [FILE]...
+
 WC Arguments:
 	-f FILENAME, --filename FILENAME 
 						The input file, or standard input (if no file is specified) to the standard output.
@@ -18,6 +24,64 @@ WC Arguments:
 	-l, --lines         The number of lines in each input file is written to the standard output.
 	-m, --characters    The number of characters in each input file is written to the standard output.
 	-w, --words         The number of words in each input file is written to the standard output.
+	-L, --max-line-length
+	                    The display width of the longest line in each input file is written to the standard output.
+	    --words-as MODE How words are told apart: posix (separated by white space, default), unicode (Unicode word
+	                    boundaries, leaving out punctuation) or code (identifiers, leaving out numbers & operators).
+	    --graphemes     The number of grapheme clusters (user-perceived characters) in each input file is written to the standard output.
+	    --invalid       The number of byte sequences which aren't characters in the encoding is written to the standard output.
+	    --encoding ENC  The encoding of the input files: utf-8 (default), utf-16 (byte order from the BOM), utf-16le, utf-16be or latin-1.
+	    --match REGEX   Write the number of lines matching REGEX (like grep -c), of occurrences of REGEX & of bytes
+	                    matched. The lines, words and bytes are then only written when asked for.
+	    --invert        With --match, count the lines which don't match REGEX instead (like grep -vc).
+	    --top N         Write the N most frequent words of all the inputs, as told apart by --words-as,
+	                    without the punctuation around them.
+	    --fold-case     With --top, count the words whatever their case.
+	    --stop-words L  With --top, leave out the words of L: english for a built-in list of common English words,
+	                    or a file of words separated by white space (may be repeated).
+	    --histogram     Write how many lines of all the inputs have every display width, in buckets.
+	    --bucket N      The width of the --histogram buckets (default 10).
+	    --compression C How the inputs are decompressed before counting: auto (gzip, bzip2 & zstd detected from
+	                    their first bytes, the default), none, gzip, bzip2 or zstd.
+	    --compressed-bytes
+	                    With -c, write the number of bytes of the compressed inputs instead of the decompressed contents.
+	    --offset N      Only count the bytes of every input from byte N on (counted from 0), sought rather than read
+	                    in regular files. For compressed inputs, N is an offset in the decompressed contents.
+	    --length N      Only count N bytes of every input, from --offset on (N > 0).
+	    --from-line N   Only count the lines of every input from line N on (counted from 1), within the bytes
+	                    selected by --offset and --length.
+	    --to-line N     Only count the lines of every input up to line N, included.
+	-j, --jobs N        Count up to N files at the same time, or N parts of a single large file (0 uses every CPU, default 1).
+	-r, --recursive     Count the files below the directories given, with a subtotal for every directory.
+	    --include GLOB  With -r, only count the files matching GLOB (may be repeated).
+	    --exclude GLOB  With -r, leave out the files & directories matching GLOB (may be repeated).
+	    --gitignore     With -r, leave out the files ignored by .gitignore files and the .git directories.
+	    --skip-binary   With -r, leave out the files which look binary.
+	    --files0-from F Read the names of the input files from F, separated by NUL characters (- for standard input).
+	    --files-from F  Read the names of the input files from F, one per line (- for standard input).
+	    --rev REV       Count the files as they are at the git revision REV, read from the repository. The files
+	                    given are paths below which the files tracked at REV are counted (all of them by default).
+	    --diff A..B     Write how the counts of every file changed between the git revisions A and B, and of their
+	                    total, as signed numbers. A.. is A..HEAD, like in git; with --diff A, the changes are from A to
+	                    the working tree.
+	    --follow        Keep counting the files as they grow, writing their counts and the lines appended per second
+	                    every interval until interrupted. Truncated & rotated files are counted again from their start.
+	                    Compressed files can't be followed unless counted as they are with --compression none.
+	    --interval D    The time between two updates of --follow, like 500ms or 5s (default 1s).
+	    --content MODE  Write the structure of data files: records, fields & malformed records of csv or tsv files
+	                    (RFC 4180), records & malformed lines of jsonl files, headings, paragraphs & code blocks of
+	                    markdown files. auto takes the mode of every file from its extension.
+	    --readability   Write the sentences, paragraphs and estimated syllables of prose, with its Flesch reading
+	                    ease (100 is easy, 0 hard) and Flesch-Kincaid grade level.
+	    --sloc          Write the code, comment and blank lines of source files, with their sums by language. The
+	                    language is taken from the file name or extension, or from the #! line.
+	    --stat          Write the metadata of every file too: permissions, last modification, size, owner, group,
+	                    inode, links, symlink target, MIME type and whether it looks binary.
+	    --output FORMAT The format of the counts: plain (GNU wc columns, the default), table, json or csv.
+	    --interactive   Ask for the filename and the counters through [y/n] prompts.
+	-h, --help          Display this help and exit.
+
+With no counter selected, the lines, words and bytes are written (like GNU wc).
 `
 
 // Throwing the custom error while parsing arguments
@@ -36,48 +100,195 @@ func ConvertStrToBool(s string) (bool, error) {
 	return false, ErrParse
 }
 
-func wc(filename string, _bytes bool, _lines bool, _characters bool, _words bool) {
-	res, err := utils.GetFileInformation(filename)
-	if err != nil {
-		log.Fatal(err)
+// Name used on the command line for the standard input
+const Stdin string = "-"
+
+// fileOptions returns the options for counting the named input, with the modes taken from its name
+func fileOptions(filename string, options utils.CountOptions) utils.CountOptions {
+	if options.Content == utils.ContentAuto {
+		options.Content = utils.DetectContentMode(filename)
 	}
-	fmt.Println(res)
+	// the language of a file without a known name or extension is taken from its #! line
+	if options.SLOC && filename != Stdin {
+		options.Language = utils.DetectLanguage(filename)
+	}
+	return options
+}
 
-	// get the size of the file
-	if _bytes {
-		res, err := utils.GetFileSize(filename)
-		if err != nil {
-			log.Fatal(err)
+// countFile computes the counts of a single input, in up to chunks concurrent byte ranges for a large file
+func countFile(ctx context.Context, filename string, chunks int, options utils.CountOptions) (utils.Counts, error) {
+	options = fileOptions(filename, options)
+	if filename == Stdin {
+		return wordcount.Count(ctx, os.Stdin, wordcount.Options{CountOptions: options})
+	}
+	return wordcount.CountFile(ctx, filename, wordcount.Options{CountOptions: options, Jobs: chunks})
+}
+
+// counter returns how the inputs are counted: read from the file system, or as they are at the --rev revision
+func (opts options) counter() countFunc {
+	options := opts.countOptions()
+	if opts.rev != "" {
+		return func(ctx context.Context, filename string, chunks int) (utils.Counts, error) {
+			return countRevision(ctx, opts.rev, filename, options)
 		}
-		fmt.Println(res)
 	}
+	return func(ctx context.Context, filename string, chunks int) (utils.Counts, error) {
+		return countFile(ctx, filename, chunks, options)
+	}
+}
 
-	// get the total no of lines in the file
-	if _lines {
-		res, err := utils.GetFileLines(filename)
+// readFileList reads the names listed in listFile ("-" for the standard input).
+// Invalid names are reported on stderr and left out, ok is false if there were any.
+func readFileList(listFile string, separator byte) ([]string, bool) {
+	var list io.Reader = os.Stdin
+	if listFile != Stdin {
+		file, err := os.Open(listFile)
 		if err != nil {
-			log.Fatal(err)
+			fmt.Fprintf(os.Stderr, "wc: cannot open '%s' for reading: %s\n", listFile, errorMessage(err))
+			return nil, false
+		}
+		defer file.Close()
+		list = file
+	}
+
+	names, err := utils.ReadFileList(list, separator)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wc: %s: %s\n", listFile, errorMessage(err))
+		return nil, false
+	}
+
+	ok := true
+	valid := make([]string, 0, len(names))
+	for i, name := range names {
+		switch {
+		case name == "":
+			fmt.Fprintf(os.Stderr, "wc: %s:%d: invalid zero-length file name\n", listFile, i+1)
+			ok = false
+		case name == Stdin && listFile == Stdin:
+			fmt.Fprintf(os.Stderr, "wc: when reading file names from stdin, no file name of '%s' allowed\n", Stdin)
+			ok = false
+		default:
+			valid = append(valid, name)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, ok
+	}
+	return valid, ok
+}
+
+// wc counts every input and prints a row per file plus a total when there is more than one.
+// Unreadable files are reported on stderr and skipped; the returned exit status is 1 if any failed.
+func wc(opts options) int {
+	status := 0
+
+	// take the names from --files0-from / --files-from
+	if opts.filesFrom != "" {
+		listed, ok := readFileList(opts.filesFrom, opts.filesFromSep)
+		if !ok {
+			status = 1
 		}
-		fmt.Println(res)
+		if listed == nil {
+			return status
+		}
+		opts.files = listed
+	}
+
+	// take the names of the files tracked at the --rev revision below every path, in the order given
+	if opts.rev != "" {
+		paths := opts.files
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		var tracked []string
+		for _, path := range paths {
+			files, err := utils.GitFiles(context.Background(), opts.rev, []string{path})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "wc: %v\n", err)
+				return 1
+			}
+			if len(files) == 0 {
+				fmt.Fprintf(os.Stderr, "wc: %s: no such file at %s\n", path, opts.rev)
+				status = 1
+			}
+			tracked = append(tracked, files...)
+		}
+		if len(tracked) == 0 {
+			return status
+		}
+		opts.files = tracked
+	}
+
+	// read from the standard input if no file is specified, without printing a name for it
+	files := opts.files
+	if len(files) == 0 {
+		files = []string{Stdin}
+	}
+
+	// walk the directories given with -r
+	files, sums, walkStatus := expandInputs(files, opts)
+	status = max(status, walkStatus)
+
+	output, err := newReporter(os.Stdout, files, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wc: %v\n", err)
+		return 1
 	}
 
-	// get the total no of chars in the file
-	if _characters {
-		res, err := utils.GetFileCharacters(filename)
+	// the standard input has no file information to show, and what could be
+	// read of a file is still shown when the rest can't be
+	for _, filename := range files {
+		if !opts.stat || filename == Stdin {
+			continue
+		}
+		info, err := utils.GetFileInformation(filename)
 		if err != nil {
-			log.Fatal(err)
+			fmt.Fprintf(os.Stderr, "wc: %s: cannot read file information: %s\n", filename, errorMessage(err))
+			status = 1
+		}
+		if info.Name != "" {
+			output.information(filename, info)
 		}
-		fmt.Println(res)
 	}
 
-	// get the total no of words in the file
-	if _words {
-		res, err := utils.GetFileWords(filename)
+	var total utils.Counts
+
+	// an interrupt stops counting, the files not counted yet are reported as errors
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	countFiles(ctx, files, opts.jobs, opts.counter(), func(i int, filename string, counts utils.Counts, err error) {
 		if err != nil {
-			log.Fatal(err)
+			fmt.Fprintf(os.Stderr, "wc: %s: %s\n", filename, errorMessage(err))
+			status = 1
+		} else {
+			total.Add(counts)
+			sums.add(i, filename, counts)
 		}
-		fmt.Println(res)
+
+		name := filename
+		if len(opts.files) == 0 {
+			name = ""
+		}
+		output.counts(name, counts, err)
+	})
+
+	sums.report(output)
+
+	if len(files) > 1 {
+		output.total(total)
 	}
+
+	if opts.distributions() {
+		output.distributions(total)
+	}
+
+	if err := output.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "wc: %v\n", err)
+		status = 1
+	}
+
+	return status
 }
 
 func checkFileExists(filepath string) (bool, error) {
@@ -91,8 +302,8 @@ func checkFileExists(filepath string) (bool, error) {
 	}
 }
 
-// main function
-func main() {
+// interactive asks for the filename and the counters through [y/n] prompts
+func interactive() {
 
 	// Print arguments required from command line
 	fmt.Print(Message)
@@ -143,6 +354,39 @@ func main() {
 		}
 
 		// Compute the file operations
-		wc(filename, _bytesBool, _linesBool, _charactersBool, _wordsBool)
+		os.Exit(wc(options{bytes: _bytesBool, lines: _linesBool, characters: _charactersBool, words: _wordsBool, stat: true, files: []string{filename}}))
+	}
+}
+
+// main function
+func main() {
+
+	// Parse the flags & filenames from command line
+	opts, err := parseArgs(os.Args[1:])
+	if err != nil {
+		printParseError(os.Stderr, err)
+		os.Exit(1)
 	}
+
+	if opts.help {
+		fmt.Print(Message)
+		return
+	}
+
+	// the old prompt flow is only used when asked for explicitly
+	if opts.interactive {
+		interactive()
+		return
+	}
+
+	if opts.follow {
+		os.Exit(follow(opts))
+	}
+
+	if opts.diff != "" {
+		os.Exit(diff(opts))
+	}
+
+	// Compute the file operations for every file
+	os.Exit(wc(opts))
 }
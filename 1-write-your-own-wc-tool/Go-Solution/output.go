@@ -0,0 +1,676 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+)
+
+// Formats accepted by --output
+const (
+	OutputPlain string = "plain"
+	OutputTable string = "table"
+	OutputJSON  string = "json"
+	OutputCSV   string = "csv"
+)
+
+// Throwing the custom error for an unknown --output format
+var ErrOutputFormat = errors.New("unknown output format, expected plain, table, json or csv")
+
+// reporter writes the results of a run in one of the --output formats
+type reporter interface {
+	// information is called for every file before any of them is counted
+	information(name string, info utils.FileInformation)
+	// counts is called for every input in the order they were given
+	counts(name string, counts utils.Counts, err error)
+	// subtotal is called for every walked directory after the files
+	subtotal(dir string, counts utils.Counts)
+	// total is called once after every input when there is more than one
+	total(counts utils.Counts)
+	// distributions is called once with the sum of every input for --top, --histogram & --sloc
+	distributions(counts utils.Counts)
+	// flush writes anything still buffered
+	flush() error
+}
+
+// newReporter returns the reporter for the --output format
+func newReporter(w io.Writer, files []string, opts options) (reporter, error) {
+	switch opts.output {
+	case "", OutputPlain:
+		return &plainReporter{w: w, opts: opts, width: numberWidth(files, opts)}, nil
+	case OutputTable:
+		return newTableReporter(w, opts), nil
+	case OutputJSON:
+		return &jsonReporter{w: w, opts: opts, infos: map[string]*jsonInformation{}}, nil
+	case OutputCSV:
+		return newCSVReporter(w, opts), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrOutputFormat, opts.output)
+}
+
+// column is a single selected counter with its name
+type column struct {
+	name  string
+	value int64
+	// readability scores aren't counts, they are written with one decimal instead of value
+	score *float64
+	// the changes of --diff are written with their sign in the formats for people
+	delta bool
+}
+
+// String returns the value as written in every format but json
+func (c column) String() string {
+	if c.score != nil {
+		return strconv.FormatFloat(*c.score, 'f', 1, 64)
+	}
+	if c.delta && c.value > 0 {
+		return "+" + strconv.FormatInt(c.value, 10)
+	}
+	return strconv.FormatInt(c.value, 10)
+}
+
+// columns returns the selected counters in GNU wc column order
+func columns(counts utils.Counts, opts options) []column {
+	readingEase, gradeLevel := counts.ReadingEase(), counts.GradeLevel()
+	all := []struct {
+		on bool
+		column
+	}{
+		{opts.lines, column{name: "lines", value: counts.Lines}},
+		{opts.words, column{name: "words", value: counts.Words}},
+		{opts.characters, column{name: "characters", value: counts.Characters}},
+		{opts.graphemes, column{name: "graphemes", value: counts.Graphemes}},
+		{opts.bytes, column{name: "bytes", value: counts.Bytes}},
+		{opts.maxLineLength, column{name: "max_line_length", value: counts.MaxLineLength}},
+		{opts.invalid, column{name: "invalid", value: counts.Invalid}},
+		{opts.match != nil, column{name: "matching_lines", value: counts.MatchingLines}},
+		{opts.match != nil, column{name: "matches", value: counts.Matches}},
+		{opts.match != nil, column{name: "matched_bytes", value: counts.MatchedBytes}},
+		{opts.content.Has(utils.ContentCSV, utils.ContentTSV, utils.ContentJSONL), column{name: "records", value: counts.Records}},
+		{opts.content.Has(utils.ContentCSV, utils.ContentTSV), column{name: "fields", value: counts.Fields}},
+		{opts.content.Has(utils.ContentCSV, utils.ContentTSV, utils.ContentJSONL), column{name: "malformed", value: counts.Malformed}},
+		{opts.content.Has(utils.ContentMarkdown), column{name: "headings", value: counts.Headings}},
+		{opts.content.Has(utils.ContentMarkdown), column{name: "paragraphs", value: counts.Paragraphs}},
+		{opts.content.Has(utils.ContentMarkdown), column{name: "code_blocks", value: counts.CodeBlocks}},
+		{opts.sloc, column{name: "code", value: counts.CodeLines}},
+		{opts.sloc, column{name: "comment", value: counts.CommentLines}},
+		{opts.sloc, column{name: "blank", value: counts.BlankLines}},
+		{opts.readability, column{name: "sentences", value: counts.Sentences}},
+		{opts.readability, column{name: "text_paragraphs", value: counts.TextParagraphs}},
+		{opts.readability, column{name: "syllables", value: counts.Syllables}},
+		{opts.readability, column{name: "reading_ease", score: &readingEase}},
+		{opts.readability, column{name: "grade_level", score: &gradeLevel}},
+	}
+
+	selected := make([]column, 0, len(all))
+	for _, c := range all {
+		if c.on {
+			c.delta = opts.diff != "" && opts.output != OutputCSV
+			selected = append(selected, c.column)
+		}
+	}
+	return selected
+}
+
+// displayName is the name machine readable formats use, where the standard input is always "-"
+func displayName(name string) string {
+	if name == "" {
+		return Stdin
+	}
+	return name
+}
+
+// printInformation writes the --stat block shown before the counts
+func printInformation(w io.Writer, info utils.FileInformation) {
+	fmt.Fprint(w, "\nInformation",
+		"\n File Name: ", info.Name,
+		"\n Permissions: ", info.Permissions,
+		"\n Last Modified: ", info.LastModified,
+		"\n Size: ", info.Size,
+		"\n Owner: ", info.Owner,
+		"\n Group: ", info.Group,
+		"\n Inode: ", info.Inode,
+		"\n Links: ", info.Links,
+		"\n",
+	)
+	if info.SymlinkTarget != "" {
+		fmt.Fprint(w, " Symlink Target: ", info.SymlinkTarget, "\n")
+	}
+	if info.MIMEType != "" {
+		fmt.Fprint(w, " MIME Type: ", info.MIMEType, "\n Binary: ", info.Binary, "\n")
+	}
+}
+
+// informationRecord returns the --stat columns of the csv format, empty for the rows without a file
+func informationRecord(info utils.FileInformation, ok bool) []string {
+	if !ok {
+		return make([]string, len(informationHeader))
+	}
+	return []string{
+		info.Permissions.String(),
+		info.LastModified.Format(time.RFC3339),
+		strconv.FormatInt(info.Size, 10),
+		info.Owner,
+		info.Group,
+		strconv.FormatUint(info.Inode, 10),
+		strconv.FormatUint(info.Links, 10),
+		info.SymlinkTarget,
+		info.MIMEType,
+		strconv.FormatBool(info.Binary),
+	}
+}
+
+// Header of the columns of informationRecord
+var informationHeader = []string{"permissions", "last_modified", "size", "owner", "group", "inode", "links", "symlink_target", "mime_type", "binary"}
+
+// Width of the longest --histogram bar
+const histogramWidth int64 = 50
+
+// printDistributions writes the most frequent words & the line length histogram selected
+func printDistributions(w io.Writer, counts utils.Counts, opts options) {
+	if opts.top > 0 {
+		words := utils.TopWords(counts.WordFrequencies, opts.top)
+		width := 1
+		if len(words) > 0 {
+			width = len(strconv.FormatInt(words[0].Count, 10))
+		}
+		fmt.Fprintln(w, "\nTop words")
+		for _, word := range words {
+			fmt.Fprintf(w, "%*d %s\n", width, word.Count, word.Word)
+		}
+	}
+
+	if buckets := utils.Histogram(counts.LineLengths, opts.bucket); opts.histogram && len(buckets) > 0 {
+		var most int64
+		for _, bucket := range buckets {
+			most = max(most, bucket.Lines)
+		}
+		rangeWidth := len(fmt.Sprintf("%d-%d", buckets[len(buckets)-1].From, buckets[len(buckets)-1].To))
+		linesWidth := len(strconv.FormatInt(most, 10))
+
+		fmt.Fprintln(w, "\nLine lengths")
+		for _, bucket := range buckets {
+			bar := strings.Repeat("#", int((bucket.Lines*histogramWidth+most-1)/most))
+			row := fmt.Sprintf("%*s %*d %s", rangeWidth, fmt.Sprintf("%d-%d", bucket.From, bucket.To), linesWidth, bucket.Lines, bar)
+			fmt.Fprintln(w, strings.TrimRight(row, " "))
+		}
+	}
+
+	if opts.sloc {
+		names := languageNames(counts.Languages)
+		nameWidth := len("LANGUAGE")
+		for _, name := range names {
+			nameWidth = max(nameWidth, len(name))
+		}
+		fmt.Fprintln(w, "\nLanguages")
+		fmt.Fprintf(w, "%-*s %7s %7s %7s %7s\n", nameWidth, "LANGUAGE", "FILES", "BLANK", "COMMENT", "CODE")
+		for _, name := range names {
+			lines := counts.Languages[name]
+			fmt.Fprintf(w, "%-*s %7d %7d %7d %7d\n", nameWidth, name, lines.Files, lines.Blank, lines.Comment, lines.Code)
+		}
+	}
+}
+
+// languageNames returns the languages with the most code lines first, like cloc
+func languageNames(languages map[string]utils.LanguageCounts) []string {
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if languages[names[i]].Code != languages[names[j]].Code {
+			return languages[names[i]].Code > languages[names[j]].Code
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// numberWidth returns the column width GNU wc would use for the given inputs:
+// wide enough for the total size of the regular files, and at least 7 when
+// the size of an input can't be known up front.
+func numberWidth(files []string, opts options) int {
+	if len(columns(utils.Counts{}, opts)) == 1 && len(files) == 1 {
+		return 1
+	}
+
+	// the files of --rev & --diff aren't the ones on disk
+	if opts.rev != "" || opts.diff != "" {
+		return 7
+	}
+
+	minWidth := 1
+	var size int64
+	for _, filename := range files {
+		if filename == Stdin {
+			minWidth = 7
+			continue
+		}
+		fileInfo, err := os.Stat(filename)
+		if err != nil {
+			continue
+		}
+		if fileInfo.Mode().IsRegular() && !isDecompressed(filename, opts) {
+			size += fileInfo.Size()
+		} else {
+			minWidth = 7
+		}
+	}
+	return max(len(strconv.FormatInt(size, 10)), minWidth)
+}
+
+// isDecompressed reports whether the counts of a file are taken from its decompressed contents, whose size isn't known up front
+func isDecompressed(filename string, opts options) bool {
+	switch opts.compression {
+	case utils.CompressionNone:
+		return false
+	case "", utils.CompressionAuto:
+		compression, err := utils.FileCompression(filename)
+		return err == nil && compression != utils.CompressionNone
+	}
+	return true
+}
+
+// printCounts writes one row of the selected counts in GNU wc column order
+func printCounts(w io.Writer, counts utils.Counts, name string, opts options, width int) {
+	selected := columns(counts, opts)
+
+	row := make([]string, 0, len(selected)+1)
+	for _, c := range selected {
+		row = append(row, fmt.Sprintf("%*s", width, c))
+	}
+	if name != "" {
+		row = append(row, name)
+	}
+	fmt.Fprintln(w, strings.Join(row, " "))
+}
+
+// plainReporter writes GNU wc compatible columns
+type plainReporter struct {
+	w     io.Writer
+	opts  options
+	width int
+}
+
+func (p *plainReporter) information(name string, info utils.FileInformation) {
+	printInformation(p.w, info)
+}
+
+func (p *plainReporter) counts(name string, counts utils.Counts, err error) {
+	if err == nil {
+		printCounts(p.w, counts, name, p.opts, p.width)
+	}
+}
+
+func (p *plainReporter) subtotal(dir string, counts utils.Counts) {
+	printCounts(p.w, counts, dir, p.opts, p.width)
+}
+
+func (p *plainReporter) total(counts utils.Counts) {
+	printCounts(p.w, counts, "total", p.opts, p.width)
+}
+
+func (p *plainReporter) distributions(counts utils.Counts) {
+	printDistributions(p.w, counts, p.opts)
+}
+
+func (p *plainReporter) flush() error {
+	return nil
+}
+
+// tableReporter writes the counts under a header, aligned on the widest value of each column
+type tableReporter struct {
+	w     io.Writer
+	table *tabwriter.Writer
+	opts  options
+}
+
+func newTableReporter(w io.Writer, opts options) *tableReporter {
+	t := &tableReporter{w: w, table: tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight), opts: opts}
+
+	for _, c := range columns(utils.Counts{}, opts) {
+		fmt.Fprintf(t.table, "%s\t", strings.ToUpper(c.name))
+	}
+	fmt.Fprintln(t.table, "  FILE")
+	return t
+}
+
+func (t *tableReporter) information(name string, info utils.FileInformation) {
+	printInformation(t.w, info)
+}
+
+func (t *tableReporter) counts(name string, counts utils.Counts, err error) {
+	if err == nil {
+		t.row(displayName(name), counts)
+	}
+}
+
+func (t *tableReporter) subtotal(dir string, counts utils.Counts) {
+	t.row(dir, counts)
+}
+
+func (t *tableReporter) total(counts utils.Counts) {
+	t.row("total", counts)
+}
+
+func (t *tableReporter) distributions(counts utils.Counts) {
+	// the rows are written first, so they don't share their alignment
+	t.table.Flush()
+	printDistributions(t.w, counts, t.opts)
+}
+
+func (t *tableReporter) row(name string, counts utils.Counts) {
+	for _, c := range columns(counts, t.opts) {
+		fmt.Fprintf(t.table, "%s\t", c)
+	}
+	fmt.Fprintln(t.table, " ", name)
+}
+
+func (t *tableReporter) flush() error {
+	return t.table.Flush()
+}
+
+// jsonCounts holds the selected counters, the others are left out
+type jsonCounts struct {
+	Lines         *int64 `json:"lines,omitempty"`
+	Words         *int64 `json:"words,omitempty"`
+	Characters    *int64 `json:"characters,omitempty"`
+	Graphemes     *int64 `json:"graphemes,omitempty"`
+	Bytes         *int64 `json:"bytes,omitempty"`
+	MaxLineLength *int64 `json:"max_line_length,omitempty"`
+	Invalid       *int64 `json:"invalid,omitempty"`
+	MatchingLines *int64 `json:"matching_lines,omitempty"`
+	Matches       *int64 `json:"matches,omitempty"`
+	MatchedBytes  *int64 `json:"matched_bytes,omitempty"`
+	Code          *int64 `json:"code,omitempty"`
+	Comment       *int64 `json:"comment,omitempty"`
+	Blank         *int64 `json:"blank,omitempty"`
+	Records       *int64 `json:"records,omitempty"`
+	Fields        *int64 `json:"fields,omitempty"`
+	Malformed     *int64 `json:"malformed,omitempty"`
+	Headings      *int64 `json:"headings,omitempty"`
+	Paragraphs    *int64 `json:"paragraphs,omitempty"`
+	CodeBlocks    *int64 `json:"code_blocks,omitempty"`
+
+	Sentences      *int64   `json:"sentences,omitempty"`
+	TextParagraphs *int64   `json:"text_paragraphs,omitempty"`
+	Syllables      *int64   `json:"syllables,omitempty"`
+	ReadingEase    *float64 `json:"reading_ease,omitempty"`
+	GradeLevel     *float64 `json:"grade_level,omitempty"`
+}
+
+func newJSONCounts(counts utils.Counts, opts options) *jsonCounts {
+	selected := &jsonCounts{}
+	if opts.lines {
+		selected.Lines = &counts.Lines
+	}
+	if opts.words {
+		selected.Words = &counts.Words
+	}
+	if opts.characters {
+		selected.Characters = &counts.Characters
+	}
+	if opts.graphemes {
+		selected.Graphemes = &counts.Graphemes
+	}
+	if opts.bytes {
+		selected.Bytes = &counts.Bytes
+	}
+	if opts.maxLineLength {
+		selected.MaxLineLength = &counts.MaxLineLength
+	}
+	if opts.invalid {
+		selected.Invalid = &counts.Invalid
+	}
+	if opts.match != nil {
+		selected.MatchingLines = &counts.MatchingLines
+		selected.Matches = &counts.Matches
+		selected.MatchedBytes = &counts.MatchedBytes
+	}
+	if opts.content.Has(utils.ContentCSV, utils.ContentTSV, utils.ContentJSONL) {
+		selected.Records = &counts.Records
+		selected.Malformed = &counts.Malformed
+	}
+	if opts.content.Has(utils.ContentCSV, utils.ContentTSV) {
+		selected.Fields = &counts.Fields
+	}
+	if opts.content.Has(utils.ContentMarkdown) {
+		selected.Headings = &counts.Headings
+		selected.Paragraphs = &counts.Paragraphs
+		selected.CodeBlocks = &counts.CodeBlocks
+	}
+	if opts.readability {
+		// rounded like the other formats
+		readingEase := math.Round(counts.ReadingEase()*10) / 10
+		gradeLevel := math.Round(counts.GradeLevel()*10) / 10
+		selected.Sentences = &counts.Sentences
+		selected.TextParagraphs = &counts.TextParagraphs
+		selected.Syllables = &counts.Syllables
+		selected.ReadingEase, selected.GradeLevel = &readingEase, &gradeLevel
+	}
+	if opts.sloc {
+		selected.Code = &counts.CodeLines
+		selected.Comment = &counts.CommentLines
+		selected.Blank = &counts.BlankLines
+	}
+	return selected
+}
+
+type jsonInformation struct {
+	Name          string    `json:"name"`
+	Permissions   string    `json:"permissions"`
+	LastModified  time.Time `json:"last_modified"`
+	Size          int64     `json:"size"`
+	Owner         string    `json:"owner,omitempty"`
+	Group         string    `json:"group,omitempty"`
+	Inode         uint64    `json:"inode,omitempty"`
+	Links         uint64    `json:"links,omitempty"`
+	SymlinkTarget string    `json:"symlink_target,omitempty"`
+	MIMEType      string    `json:"mime_type,omitempty"`
+	Binary        bool      `json:"binary"`
+}
+
+type jsonFile struct {
+	Name     string `json:"name"`
+	Language string `json:"language,omitempty"`
+	*jsonCounts
+	Information *jsonInformation `json:"information,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+type jsonDirectory struct {
+	Name string `json:"name"`
+	*jsonCounts
+}
+
+type jsonWordCount struct {
+	Word  string `json:"word"`
+	Count int64  `json:"count"`
+}
+
+type jsonBucket struct {
+	From  int64 `json:"from"`
+	To    int64 `json:"to"`
+	Lines int64 `json:"lines"`
+}
+
+type jsonLanguage struct {
+	Language string `json:"language"`
+	Files    int64  `json:"files"`
+	Blank    int64  `json:"blank"`
+	Comment  int64  `json:"comment"`
+	Code     int64  `json:"code"`
+}
+
+type jsonReport struct {
+	Files       []jsonFile      `json:"files"`
+	Directories []jsonDirectory `json:"directories,omitempty"`
+	Total       *jsonCounts     `json:"total"`
+	TopWords    []jsonWordCount `json:"top_words,omitempty"`
+	LineLengths []jsonBucket    `json:"line_lengths,omitempty"`
+	Languages   []jsonLanguage  `json:"languages,omitempty"`
+}
+
+// jsonReporter collects every file and writes a single JSON document on flush
+type jsonReporter struct {
+	w      io.Writer
+	opts   options
+	infos  map[string]*jsonInformation
+	sum    utils.Counts
+	report jsonReport
+}
+
+func (j *jsonReporter) information(name string, info utils.FileInformation) {
+	j.infos[name] = &jsonInformation{
+		Name:         info.Name,
+		Permissions:  info.Permissions.String(),
+		LastModified: info.LastModified,
+		Size:         info.Size,
+
+		Owner:         info.Owner,
+		Group:         info.Group,
+		Inode:         info.Inode,
+		Links:         info.Links,
+		SymlinkTarget: info.SymlinkTarget,
+		MIMEType:      info.MIMEType,
+		Binary:        info.Binary,
+	}
+}
+
+func (j *jsonReporter) counts(name string, counts utils.Counts, err error) {
+	file := jsonFile{Name: displayName(name), Information: j.infos[name]}
+	if err != nil {
+		file.Error = errorMessage(err)
+	} else {
+		file.jsonCounts = newJSONCounts(counts, j.opts)
+		// a file has a single language
+		for name := range counts.Languages {
+			file.Language = name
+		}
+		j.sum.Add(counts)
+	}
+	j.report.Files = append(j.report.Files, file)
+}
+
+func (j *jsonReporter) subtotal(dir string, counts utils.Counts) {
+	j.report.Directories = append(j.report.Directories, jsonDirectory{Name: dir, jsonCounts: newJSONCounts(counts, j.opts)})
+}
+
+func (j *jsonReporter) total(counts utils.Counts) {
+	j.report.Total = newJSONCounts(counts, j.opts)
+}
+
+func (j *jsonReporter) distributions(counts utils.Counts) {
+	if j.opts.top > 0 {
+		j.report.TopWords = []jsonWordCount{}
+		for _, word := range utils.TopWords(counts.WordFrequencies, j.opts.top) {
+			j.report.TopWords = append(j.report.TopWords, jsonWordCount{Word: word.Word, Count: word.Count})
+		}
+	}
+	if j.opts.histogram {
+		for _, bucket := range utils.Histogram(counts.LineLengths, j.opts.bucket) {
+			j.report.LineLengths = append(j.report.LineLengths, jsonBucket{From: bucket.From, To: bucket.To, Lines: bucket.Lines})
+		}
+	}
+	if j.opts.sloc {
+		j.report.Languages = []jsonLanguage{}
+		for _, name := range languageNames(counts.Languages) {
+			lines := counts.Languages[name]
+			j.report.Languages = append(j.report.Languages, jsonLanguage{Language: name, Files: lines.Files, Blank: lines.Blank, Comment: lines.Comment, Code: lines.Code})
+		}
+	}
+}
+
+func (j *jsonReporter) flush() error {
+	// the total is always part of the document, even for a single input
+	if j.report.Total == nil {
+		j.report.Total = newJSONCounts(j.sum, j.opts)
+	}
+	if j.report.Files == nil {
+		j.report.Files = []jsonFile{}
+	}
+	encoder := json.NewEncoder(j.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(j.report)
+}
+
+// csvReporter writes a header and one record per file, with the --stat information in the last columns
+type csvReporter struct {
+	w     *csv.Writer
+	opts  options
+	infos map[string]utils.FileInformation
+}
+
+func newCSVReporter(w io.Writer, opts options) *csvReporter {
+	c := &csvReporter{w: csv.NewWriter(w), opts: opts, infos: map[string]utils.FileInformation{}}
+
+	header := []string{"name"}
+	for _, col := range columns(utils.Counts{}, opts) {
+		header = append(header, col.name)
+	}
+	if opts.stat {
+		header = append(header, informationHeader...)
+	}
+	c.w.Write(header)
+	return c
+}
+
+func (c *csvReporter) information(name string, info utils.FileInformation) {
+	c.infos[name] = info
+}
+
+func (c *csvReporter) counts(name string, counts utils.Counts, err error) {
+	if err != nil {
+		return
+	}
+	info, ok := c.infos[name]
+	c.record(displayName(name), counts, info, ok)
+}
+
+func (c *csvReporter) subtotal(dir string, counts utils.Counts) {
+	c.record(dir, counts, utils.FileInformation{}, false)
+}
+
+func (c *csvReporter) total(counts utils.Counts) {
+	c.record("total", counts, utils.FileInformation{}, false)
+}
+
+// the csv format doesn't take --top, --histogram & the --sloc languages, see parseArgs
+func (c *csvReporter) distributions(counts utils.Counts) {}
+
+func (c *csvReporter) record(name string, counts utils.Counts, info utils.FileInformation, hasInfo bool) {
+	record := []string{name}
+	for _, col := range columns(counts, c.opts) {
+		record = append(record, col.String())
+	}
+	if c.opts.stat {
+		record = append(record, informationRecord(info, hasInfo)...)
+	}
+	c.w.Write(record)
+}
+
+func (c *csvReporter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// errorMessage strips the operation & path from file errors, as the filename is already reported
+func errorMessage(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
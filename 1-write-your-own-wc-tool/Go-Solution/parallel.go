@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+)
+
+// countFunc computes the counts of a single input, in up to chunks concurrent byte ranges where it can
+type countFunc func(ctx context.Context, filename string, chunks int) (utils.Counts, error)
+
+// countFiles counts the files with count on up to jobs goroutines (all the
+// CPUs for 0) and calls emit for every file in input order, as soon as it and the files
+// before it are done. A single large file is split into jobs byte ranges
+// counted concurrently instead.
+func countFiles(ctx context.Context, files []string, jobs int, count countFunc, emit func(i int, filename string, counts utils.Counts, err error)) {
+	if jobs == 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	if len(files) == 1 {
+		counts, err := count(ctx, files[0], jobs)
+		emit(0, files[0], counts, err)
+		return
+	}
+
+	type result struct {
+		counts utils.Counts
+		err    error
+		done   chan struct{}
+	}
+	results := make([]result, len(files))
+	for i := range results {
+		results[i].done = make(chan struct{})
+	}
+
+	// the pool of workers takes the files in input order
+	queue := make(chan int)
+	for worker := 0; worker < min(jobs, len(files)); worker++ {
+		go func() {
+			for i := range queue {
+				results[i].counts, results[i].err = count(ctx, files[i], 1)
+				close(results[i].done)
+			}
+		}()
+	}
+	go func() {
+		for i := range files {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	// report in input order, so the output & the total don't depend on scheduling
+	for i := range results {
+		<-results[i].done
+		emit(i, files[i], results[i].counts, results[i].err)
+	}
+}
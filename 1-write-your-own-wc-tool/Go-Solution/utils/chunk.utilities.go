@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+// Smallest byte range worth counting on its own goroutine
+const MinChunkSize int64 = 4 * 1024 * 1024
+
+// smallest byte range CountChunks splits off, lowered by the tests to split small inputs
+var minChunkSize = MinChunkSize
+
+// CountChunks counts the first size bytes of r, or the part of them selected
+// by options.Range, by splitting them into up to chunks byte ranges that are
+// counted concurrently. Runes, words and lines
+// straddling two ranges are stitched back together, so the result is the
+// same as a sequential CountReader over the same bytes.
+func CountChunks(r io.ReaderAt, size int64, chunks int, options CountOptions) (Counts, error) {
+	// UTF-16 inputs are only counted sequentially, from their byte order mark on,
+	// and grapheme clusters, the other word modes, frequencies, histograms
+	// & lines matched against a pattern, classified as code or data or read
+	// as prose can't be stitched back together
+	switch options.Encoding {
+	case UTF16, UTF16LE, UTF16BE:
+		chunks = 1
+	}
+	if options.Graphemes || options.Words != "" && options.Words != WordsPOSIX || options.WordFrequencies || options.LineHistogram || options.Match != nil || options.SLOC || options.Content != "" || options.Readability {
+		chunks = 1
+	}
+
+	// a compressed input can only be decompressed from its start, and its
+	// range is selected from the decompressed contents by CountReader
+	if (chunks > 1 || !options.Range.IsZero()) && options.Compression != CompressionNone {
+		header := make([]byte, compressionHeaderSize)
+		n, err := r.ReadAt(header, 0)
+		if err != nil && err != io.EOF {
+			return Counts{}, fmt.Errorf("error reading input: %w", err)
+		}
+		if options.Compression != "" && options.Compression != CompressionAuto || DetectCompression(header[:n]) != CompressionNone {
+			return CountReader(io.NewSectionReader(r, 0, size), options)
+		}
+	}
+
+	// the range of any other input is sought, and split into chunks itself
+	if !options.Range.IsZero() {
+		if err := options.Range.check(options.Encoding); err != nil {
+			return Counts{}, err
+		}
+		offset, length, err := options.Range.section(r, size)
+		if err != nil {
+			return Counts{}, err
+		}
+		r, size = io.NewSectionReader(r, offset, length), length
+		options.Range = Range{}
+	}
+
+	chunks = int(min(int64(chunks), size/minChunkSize))
+	if chunks <= 1 {
+		return CountReader(io.NewSectionReader(r, 0, size), options)
+	}
+
+	// split on rune starts, so no chunk begins in the middle of a multi-byte rune
+	bounds := make([]int64, chunks+1)
+	bounds[chunks] = size
+	for i := 1; i < chunks; i++ {
+		start, err := runeStart(r, int64(i)*(size/int64(chunks)), size)
+		if err != nil {
+			return Counts{}, fmt.Errorf("error reading input: %w", err)
+		}
+		bounds[i] = max(start, bounds[i-1])
+	}
+
+	counters := make([]*Counter, chunks)
+	errs := make([]error, chunks)
+	var wg sync.WaitGroup
+	for i := range counters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counters[i] = NewCounter(options)
+			section := &byteCounter{r: io.NewSectionReader(r, bounds[i], bounds[i+1]-bounds[i]), onRead: options.OnRead}
+			if _, err := io.CopyBuffer(counters[i], section, make([]byte, 64*1024)); err != nil {
+				errs[i] = fmt.Errorf("error reading input: %w", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return Counts{}, err
+		}
+	}
+	return stitch(counters), nil
+}
+
+// runeStart returns the first offset at or after offset which doesn't hold a
+// UTF-8 continuation byte, looking at most utf8.UTFMax-1 bytes ahead: a
+// continuation byte further away can't belong to a valid rune anyway.
+func runeStart(r io.ReaderAt, offset int64, size int64) (int64, error) {
+	buffer := make([]byte, utf8.UTFMax-1)
+	n, err := r.ReadAt(buffer, offset)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	for i := 0; i < n; i++ {
+		if !utf8.RuneStart(buffer[i]) {
+			continue
+		}
+		return offset + int64(i), nil
+	}
+	return min(offset+int64(n), size), nil
+}
+
+// stitch adds up the counters of consecutive chunks
+func stitch(counters []*Counter) Counts {
+	var total Counts
+	var lineLength int64
+	inWord := false
+
+	for _, counter := range counters {
+		counts := counter.Counts()
+
+		// a word running over the boundary was counted by both chunks
+		if inWord && counter.startsInWord {
+			counts.Words--
+		}
+		if counter.started {
+			inWord = counter.inWord
+		}
+
+		// the line running over the boundary was split between both chunks
+		if counter.sawLineBreak {
+			counts.MaxLineLength = max(counts.MaxLineLength, extendLine(lineLength, counter, counter.firstLineLength))
+			lineLength = counter.lineLength
+		} else {
+			lineLength = extendLine(lineLength, counter, counter.lineLength)
+		}
+
+		total.Add(counts)
+	}
+
+	total.MaxLineLength = max(total.MaxLineLength, lineLength)
+	return total
+}
+
+// extendLine returns the width of a line which was column wide at the end of
+// the previous chunk and continues with the first length columns of counter,
+// which were measured from column 0. Tabs are the only characters whose width
+// depends on where they start: everything after the first one is aligned on a
+// tab stop either way.
+func extendLine(column int64, counter *Counter, length int64) int64 {
+	if counter.firstTabColumn < 0 {
+		return column + length
+	}
+	return nextTabStop(column+counter.firstTabColumn) + length - nextTabStop(counter.firstTabColumn)
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// splitting an input anywhere gives the same counts as counting it in one pass
+func TestCountChunksMatchesCountReader(t *testing.T) {
+	defer func(size int64) { minChunkSize = size }(minChunkSize)
+	minChunkSize = 1
+
+	inputs := map[string]string{
+		"multi-byte runes": "héllo wörld 日本語 😀 ünïcödé\n",
+		"words":            "one two  three\nfour   five six\n",
+		"tab runs":         "a\t\tb\t\t\tc\n\t\t\t\tx\t\n ab\tcd\t\n",
+		"carriage returns": "ab\r\ncd\rxyz\r\n\r\n",
+		"invalid bytes":    "a\xffb \x80\x80\x80\x80 c\xe6\x97 d\xc3\n",
+		"no trailing line": "last line without newline\tend",
+	}
+	options := map[string]CountOptions{
+		"utf-8":      {},
+		"lines only": {LinesOnly: true},
+		"latin-1":    {Encoding: Latin1},
+	}
+
+	for name, input := range inputs {
+		for optionsName, opts := range options {
+			want, err := CountReader(bytes.NewReader([]byte(input)), opts)
+			if err != nil {
+				t.Fatalf("%s, %s: CountReader: %v", name, optionsName, err)
+			}
+			// every number of chunks up to one byte each, so a boundary falls at every offset
+			for chunks := 2; chunks <= len(input); chunks++ {
+				got, err := CountChunks(bytes.NewReader([]byte(input)), int64(len(input)), chunks, opts)
+				if err != nil {
+					t.Fatalf("%s, %s, %d chunks: CountChunks: %v", name, optionsName, chunks, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s, %s, %d chunks: got %+v, want %+v", name, optionsName, chunks, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRuneStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		offset int64
+		want   int64
+	}{
+		{"ascii", "abcd", 1, 1},
+		{"rune start", "aé", 1, 1},
+		{"inside a 2-byte rune", "aé", 2, 3},
+		{"inside a 4-byte rune", "a😀b", 2, 5},
+		{"at the last byte of a rune", "a😀b", 4, 5},
+		// stray continuation bytes can't be a rune, the search stops after utf8.UTFMax-1 bytes
+		{"continuation bytes", "a\x80\x80\x80\x80\x80b", 1, 4},
+		{"continuation bytes up to the end", "a\x80\x80", 1, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := runeStart(bytes.NewReader([]byte(test.input)), test.offset, int64(len(test.input)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("runeStart(%q, %d) = %d, want %d", test.input, test.offset, got, test.want)
+			}
+		})
+	}
+}
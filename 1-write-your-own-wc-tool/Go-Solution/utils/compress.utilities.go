@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression formats accepted by ParseCompression
+type Compression string
+
+const (
+	// the format is detected from the magic bytes at the start of the input
+	CompressionAuto Compression = "auto"
+	// the input is counted as it is, even when it looks compressed
+	CompressionNone Compression = "none"
+	Gzip            Compression = "gzip"
+	Bzip2           Compression = "bzip2"
+	Zstd            Compression = "zstd"
+)
+
+// Magic bytes starting the inputs of every compression format: gzip with the
+// deflate method, bzip2 followed by its block size & the zstd frame magic
+var compressionMagic = []struct {
+	compression Compression
+	matches     func(header []byte) bool
+}{
+	{Gzip, func(header []byte) bool {
+		// the reserved flag bits must be clear
+		return bytes.HasPrefix(header, []byte{0x1f, 0x8b, 0x08}) && (len(header) < 4 || header[3]&0xe0 == 0)
+	}},
+	{Bzip2, func(header []byte) bool {
+		return len(header) >= 4 && bytes.HasPrefix(header, []byte("BZh")) && header[3] >= '1' && header[3] <= '9'
+	}},
+	{Zstd, func(header []byte) bool {
+		return bytes.HasPrefix(header, []byte{0x28, 0xb5, 0x2f, 0xfd})
+	}},
+}
+
+// Number of bytes DetectCompression is given when they are available
+const compressionHeaderSize = 4096
+
+// Throwing the custom error for an unsupported compression
+var ErrCompression = errors.New("unsupported compression, expected auto, none, gzip, bzip2 or zstd")
+
+// ParseCompression returns the Compression for a name, ignoring case and the usual aliases
+func ParseCompression(name string) (Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "auto":
+		return CompressionAuto, nil
+	case "none", "off":
+		return CompressionNone, nil
+	case "gzip", "gz":
+		return Gzip, nil
+	case "bzip2", "bz2":
+		return Bzip2, nil
+	case "zstd", "zst":
+		return Zstd, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrCompression, name)
+}
+
+// DetectCompression returns the compression format of an input starting with
+// header, CompressionNone for none. The header must start like the format and
+// be accepted by its decompressor as far as it goes, so a text which merely
+// starts with the same bytes isn't taken for a compressed input.
+func DetectCompression(header []byte) Compression {
+	for _, format := range compressionMagic {
+		if format.matches(header) && decompresses(header, format.compression) {
+			return format.compression
+		}
+	}
+	return CompressionNone
+}
+
+// decompresses reports whether the start of a compressed input is valid, a
+// header ending before the first decompressed byte is
+func decompresses(header []byte, compression Compression) bool {
+	reader, closeReader, err := decompress(bytes.NewReader(header), compression)
+	if err == nil {
+		defer closeReader()
+		_, err = reader.Read(make([]byte, 1))
+	}
+	return err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// FileCompression returns the compression format of the named file, detected from its first bytes
+func FileCompression(filename string) (Compression, error) {
+	header, err := readHeader(filename, compressionHeaderSize)
+	if err != nil {
+		return CompressionNone, err
+	}
+	return DetectCompression(header), nil
+}
+
+// decompress returns a reader of the decompressed contents of r. With
+// CompressionAuto the format is detected from the first bytes, and an input
+// which isn't compressed, or whose header isn't valid, is read as it is.
+// Only a format given explicitly makes an invalid header an error.
+func decompress(r io.Reader, compression Compression) (io.Reader, func(), error) {
+	if compression == "" || compression == CompressionAuto {
+		buffered := bufio.NewReaderSize(r, compressionHeaderSize)
+		// a short input can't be compressed, Peek returns what there is
+		header, _ := buffered.Peek(compressionHeaderSize)
+		compression, r = DetectCompression(header), buffered
+	}
+
+	switch compression {
+	case Gzip:
+		reader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error decompressing gzip input: %w", err)
+		}
+		return reader, func() { reader.Close() }, nil
+	case Bzip2:
+		return bzip2.NewReader(r), func() {}, nil
+	case Zstd:
+		reader, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error decompressing zstd input: %w", err)
+		}
+		return reader, reader.Close, nil
+	}
+	return r, func() {}, nil
+}
+
+// byteCounter counts the bytes read through it, reporting them to onRead when not nil
+type byteCounter struct {
+	r      io.Reader
+	count  int64
+	onRead func(n int)
+}
+
+func (b *byteCounter) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.count += int64(n)
+	if b.onRead != nil && n > 0 {
+		b.onRead(n)
+	}
+	return n, err
+}
@@ -0,0 +1,324 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Content modes accepted by ParseContentMode
+type ContentMode string
+
+const (
+	// the mode of every file is taken from its extension
+	ContentAuto     ContentMode = "auto"
+	ContentCSV      ContentMode = "csv"
+	ContentTSV      ContentMode = "tsv"
+	ContentJSONL    ContentMode = "jsonl"
+	ContentMarkdown ContentMode = "markdown"
+)
+
+// Throwing the custom error for an unknown content mode
+var ErrContentMode = errors.New("unknown content mode, expected csv, tsv, jsonl, markdown or auto")
+
+// ParseContentMode returns the ContentMode for a name, ignoring case and the usual aliases
+func ParseContentMode(name string) (ContentMode, error) {
+	switch strings.ToLower(name) {
+	case "auto":
+		return ContentAuto, nil
+	case "csv":
+		return ContentCSV, nil
+	case "tsv":
+		return ContentTSV, nil
+	case "jsonl", "ndjson", "json-lines":
+		return ContentJSONL, nil
+	case "markdown", "md":
+		return ContentMarkdown, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrContentMode, name)
+}
+
+// Has reports whether the counts of one of the modes are written in this mode, ContentAuto has them all
+func (m ContentMode) Has(modes ...ContentMode) bool {
+	for _, mode := range modes {
+		if m == mode || m == ContentAuto {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectContentMode returns the content mode of a file from its extension, "" when it has none
+func DetectContentMode(filename string) ContentMode {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ContentCSV
+	case ".tsv", ".tab":
+		return ContentTSV
+	case ".jsonl", ".ndjson":
+		return ContentJSONL
+	case ".md", ".markdown":
+		return ContentMarkdown
+	}
+	return ""
+}
+
+// contentCounter counts the structure of a data file
+type contentCounter interface {
+	// add is called with every character & line is called with every line, without its newline
+	add(r rune)
+	line(text []byte)
+	// counts sets the content counts so far, as if the input ended after the last character
+	counts(counts *Counts)
+}
+
+// newContentCounter returns the counter of a content mode, nil when there is none
+func newContentCounter(mode ContentMode) contentCounter {
+	switch mode {
+	case ContentCSV:
+		return &csvCounter{delimiter: ','}
+	case ContentTSV:
+		return &csvCounter{delimiter: '\t'}
+	case ContentJSONL:
+		return &jsonLinesCounter{}
+	case ContentMarkdown:
+		return &markdownCounter{}
+	}
+	return nil
+}
+
+// States of csvCounter
+const (
+	csvRecordStart = iota
+	csvFieldStart
+	csvUnquoted
+	csvQuoted
+	// a quote in a quoted field, either closing it or escaping another quote
+	csvQuotedQuote
+)
+
+// csvCounter counts the records & fields of RFC 4180 text, where a quoted
+// field may hold delimiters, newlines & doubled quotes. Empty lines aren't
+// records, and a record with a quote out of place is malformed.
+type csvCounter struct {
+	delimiter rune
+	state     int
+	malformed bool
+
+	records        int64
+	fields         int64
+	malformedCount int64
+}
+
+func (c *csvCounter) add(r rune) {
+	if c.state == csvRecordStart {
+		if r == '\n' || r == '\r' {
+			return
+		}
+		c.records++
+		c.fields++
+		c.malformed = false
+		c.state = csvFieldStart
+	}
+
+	switch c.state {
+	case csvFieldStart:
+		switch r {
+		case '"':
+			c.state = csvQuoted
+		case c.delimiter:
+			c.fields++
+		case '\n':
+			c.state = csvRecordStart
+		default:
+			c.state = csvUnquoted
+		}
+	case csvUnquoted:
+		switch r {
+		case c.delimiter:
+			c.fields++
+			c.state = csvFieldStart
+		case '\n':
+			c.state = csvRecordStart
+		case '"':
+			c.malformedRecord()
+		}
+	case csvQuoted:
+		if r == '"' {
+			c.state = csvQuotedQuote
+		}
+	case csvQuotedQuote:
+		switch r {
+		case '"':
+			c.state = csvQuoted
+		case c.delimiter:
+			c.fields++
+			c.state = csvFieldStart
+		case '\n':
+			c.state = csvRecordStart
+		case '\r':
+		default:
+			c.malformedRecord()
+			c.state = csvUnquoted
+		}
+	}
+}
+
+func (c *csvCounter) malformedRecord() {
+	if !c.malformed {
+		c.malformed = true
+		c.malformedCount++
+	}
+}
+
+func (c *csvCounter) line(text []byte) {}
+
+func (c *csvCounter) counts(counts *Counts) {
+	counts.Records, counts.Fields, counts.Malformed = c.records, c.fields, c.malformedCount
+	// a quoted field still open at the end of the input
+	if c.state == csvQuoted && !c.malformed {
+		counts.Malformed++
+	}
+}
+
+// jsonLinesCounter counts the lines holding a JSON value, and the others
+// which aren't empty as malformed
+type jsonLinesCounter struct {
+	records   int64
+	malformed int64
+}
+
+func (j *jsonLinesCounter) add(r rune) {}
+
+func (j *jsonLinesCounter) line(text []byte) {
+	text = bytes.TrimSpace(text)
+	switch {
+	case len(text) == 0:
+	case json.Valid(text):
+		j.records++
+	default:
+		j.malformed++
+	}
+}
+
+func (j *jsonLinesCounter) counts(counts *Counts) {
+	counts.Records, counts.Malformed = j.records, j.malformed
+}
+
+// markdownCounter counts the headings, paragraphs & code blocks of
+// CommonMark text. Lists & block quotes count as paragraphs.
+type markdownCounter struct {
+	// opening fence of the fenced code block the line is in, like "```"
+	fence       string
+	inParagraph bool
+	inCode      bool
+
+	headings   int64
+	paragraphs int64
+	codeBlocks int64
+}
+
+func (m *markdownCounter) add(r rune) {}
+
+func (m *markdownCounter) line(text []byte) {
+	indent, rest := markdownIndent(text)
+	trimmed := strings.TrimSpace(string(rest))
+
+	if m.fence != "" {
+		// closed by a fence of the same character at least as long, with nothing after it
+		if indent < 4 && strings.HasPrefix(trimmed, m.fence) && strings.Trim(trimmed, m.fence[:1]) == "" {
+			m.fence = ""
+		}
+		return
+	}
+
+	switch {
+	case trimmed == "":
+		m.inParagraph = false
+	case indent >= 4 && !m.inParagraph:
+		// an indented code block goes on until a line which isn't indented
+		if !m.inCode {
+			m.codeBlocks++
+			m.inCode = true
+		}
+		return
+	case indent >= 4:
+		// a paragraph continues on an indented line
+	case markdownFence(trimmed) != "":
+		m.fence = markdownFence(trimmed)
+		m.codeBlocks++
+		m.inParagraph = false
+	case markdownHeading(trimmed):
+		m.headings++
+		m.inParagraph = false
+	case m.inParagraph && markdownUnderline(trimmed):
+		// a setext heading, the paragraph above is its text
+		m.paragraphs--
+		m.headings++
+		m.inParagraph = false
+	case markdownBreak(trimmed):
+		m.inParagraph = false
+	case !m.inParagraph:
+		m.paragraphs++
+		m.inParagraph = true
+	}
+	if trimmed != "" {
+		m.inCode = false
+	}
+}
+
+func (m *markdownCounter) counts(counts *Counts) {
+	counts.Headings, counts.Paragraphs, counts.CodeBlocks = m.headings, m.paragraphs, m.codeBlocks
+}
+
+// markdownIndent returns the width of the leading white space of a line, with tabs to the next multiple of 4
+func markdownIndent(text []byte) (int, []byte) {
+	indent := 0
+	for i, b := range text {
+		switch b {
+		case ' ':
+			indent++
+		case '\t':
+			indent += 4 - indent%4
+		default:
+			return indent, text[i:]
+		}
+	}
+	return indent, nil
+}
+
+// markdownFence returns the fence opening a fenced code block, like "```" or "~~~~", "" for none
+func markdownFence(line string) string {
+	for _, c := range []string{"`", "~"} {
+		fence := line[:len(line)-len(strings.TrimLeft(line, c))]
+		// the info string after a backtick fence can't have backticks
+		if len(fence) >= 3 && !(c == "`" && strings.Contains(line[len(fence):], "`")) {
+			return fence
+		}
+	}
+	return ""
+}
+
+// markdownHeading reports whether a line is an ATX heading, like "## Usage"
+func markdownHeading(line string) bool {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	return level >= 1 && level <= 6 && (len(line) == level || line[level] == ' ' || line[level] == '\t')
+}
+
+// markdownUnderline reports whether a line underlines a setext heading, like "====="
+func markdownUnderline(line string) bool {
+	return strings.Trim(line, "=") == "" || strings.Trim(line, "-") == ""
+}
+
+// markdownBreak reports whether a line is a thematic break, like "***" or "- - -"
+func markdownBreak(line string) bool {
+	for _, c := range []string{"*", "-", "_"} {
+		if strings.Count(line, c) >= 3 && strings.Trim(line, c+" \t") == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,525 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Counts holds every metric gathered from a single pass over an input
+type Counts struct {
+	Bytes         int64
+	Lines         int64
+	Characters    int64
+	Words         int64
+	MaxLineLength int64
+
+	// byte sequences which aren't characters in the encoding, like GNU wc
+	// they are neither counted as characters nor break or start a word
+	Invalid int64
+
+	// user-perceived characters, only counted when CountOptions.Graphemes is set
+	Graphemes int64
+
+	// lines matching CountOptions.Match, or not matching it with CountOptions.Invert,
+	// and the occurrences of the pattern in every line with their size in bytes
+	MatchingLines int64
+	Matches       int64
+	MatchedBytes  int64
+
+	// source lines of the kinds told apart by CountOptions.SLOC, with their sums by language
+	CodeLines    int64
+	CommentLines int64
+	BlankLines   int64
+	Languages    map[string]LanguageCounts
+
+	// structure of data files counted by CountOptions.Content: the records &
+	// fields of CSV, the records of JSON Lines, the records or lines which
+	// aren't well formed & the headings, paragraphs & code blocks of Markdown
+	Records    int64
+	Fields     int64
+	Malformed  int64
+	Headings   int64
+	Paragraphs int64
+	CodeBlocks int64
+
+	// prose counted by CountOptions.Readability, see ReadingEase & GradeLevel
+	Sentences      int64
+	TextParagraphs int64
+	Syllables      int64
+
+	// number of times every word occurs, only counted when CountOptions.WordFrequencies is set
+	WordFrequencies map[string]int64
+	// number of lines of every display width, only counted when CountOptions.LineHistogram is set
+	LineLengths map[int64]int64
+}
+
+// CountOptions select how the characters of an input are read
+type CountOptions struct {
+	Encoding Encoding
+	// how words are told apart, WordsPOSIX when empty
+	Words WordMode
+	// count grapheme clusters too, which is slower than counting characters
+	Graphemes bool
+	// only the lines & bytes are needed: like POSIX wc, newline bytes are
+	// counted without decoding the characters, which is much faster.
+	// Ignored for UTF-16, where a newline isn't a single byte.
+	LinesOnly bool
+	// how CountReader decompresses the input, CompressionAuto when empty
+	Compression Compression
+	// report the bytes of a compressed input rather than of its decompressed contents
+	CompressedBytes bool
+
+	// count how often every word occurs, with the case folded when FoldCase
+	// is set & leaving out the StopWords, whatever their case
+	WordFrequencies bool
+	FoldCase        bool
+	StopWords       []string
+	// count the lines of every display width
+	LineHistogram bool
+
+	// count the lines matching a pattern, like `grep -c`, or not matching it when Invert is set
+	Match  *regexp.Regexp
+	Invert bool
+
+	// tell the code, comment & blank lines of source code apart, in the
+	// Language or the one named by a #! first line when nil
+	SLOC     bool
+	Language *Language
+
+	// count the structure of a data file as well, see Counts.Records
+	Content ContentMode
+
+	// count the sentences, paragraphs & syllables of prose as well
+	Readability bool
+
+	// count only part of the input, the whole input when zero. The range
+	// applies to the decompressed contents of a compressed input.
+	Range Range
+
+	// called with the number of bytes of every read of the input feeding the
+	// counters, from any goroutine with CountChunks. The probes of CountChunks
+	// and the lines skipped by a sought Range aren't reported.
+	OnRead func(n int)
+}
+
+// Add accumulates other into c, keeping the longest line of both
+func (c *Counts) Add(other Counts) {
+	c.Bytes += other.Bytes
+	c.Lines += other.Lines
+	c.Characters += other.Characters
+	c.Words += other.Words
+	c.MaxLineLength = max(c.MaxLineLength, other.MaxLineLength)
+	c.Invalid += other.Invalid
+	c.Graphemes += other.Graphemes
+	c.MatchingLines += other.MatchingLines
+	c.Matches += other.Matches
+	c.MatchedBytes += other.MatchedBytes
+	c.CodeLines += other.CodeLines
+	c.CommentLines += other.CommentLines
+	c.BlankLines += other.BlankLines
+	c.Records += other.Records
+	c.Fields += other.Fields
+	c.Malformed += other.Malformed
+	c.Headings += other.Headings
+	c.Paragraphs += other.Paragraphs
+	c.CodeBlocks += other.CodeBlocks
+	c.Sentences += other.Sentences
+	c.TextParagraphs += other.TextParagraphs
+	c.Syllables += other.Syllables
+
+	if other.WordFrequencies != nil {
+		if c.WordFrequencies == nil {
+			c.WordFrequencies = map[string]int64{}
+		}
+		for word, count := range other.WordFrequencies {
+			c.WordFrequencies[word] += count
+		}
+	}
+	if other.Languages != nil {
+		if c.Languages == nil {
+			c.Languages = map[string]LanguageCounts{}
+		}
+		for name, lines := range other.Languages {
+			sum := c.Languages[name]
+			sum.Files += lines.Files
+			sum.Code += lines.Code
+			sum.Comment += lines.Comment
+			sum.Blank += lines.Blank
+			c.Languages[name] = sum
+		}
+	}
+	if other.LineLengths != nil {
+		if c.LineLengths == nil {
+			c.LineLengths = map[int64]int64{}
+		}
+		for length, lines := range other.LineLengths {
+			c.LineLengths[length] += lines
+		}
+	}
+}
+
+// Subtract takes the counts of other away from c, for the change between two
+// versions of an input. The frequencies, line lengths & languages are left as they are.
+func (c *Counts) Subtract(other Counts) {
+	c.Bytes -= other.Bytes
+	c.Lines -= other.Lines
+	c.Characters -= other.Characters
+	c.Words -= other.Words
+	c.MaxLineLength -= other.MaxLineLength
+	c.Invalid -= other.Invalid
+	c.Graphemes -= other.Graphemes
+	c.MatchingLines -= other.MatchingLines
+	c.Matches -= other.Matches
+	c.MatchedBytes -= other.MatchedBytes
+	c.CodeLines -= other.CodeLines
+	c.CommentLines -= other.CommentLines
+	c.BlankLines -= other.BlankLines
+	c.Records -= other.Records
+	c.Fields -= other.Fields
+	c.Malformed -= other.Malformed
+	c.Headings -= other.Headings
+	c.Paragraphs -= other.Paragraphs
+	c.CodeBlocks -= other.CodeBlocks
+	c.Sentences -= other.Sentences
+	c.TextParagraphs -= other.TextParagraphs
+	c.Syllables -= other.Syllables
+}
+
+// Counter computes the Counts of a stream in a single pass.
+// It is an io.Writer, so any reader can be copied into it chunk by chunk.
+type Counter struct {
+	counts Counts
+	inWord bool
+	// display width of the current line, see runeWidth
+	lineLength int64
+	graphemes  characterCounter
+	// counts the words instead of inWord for the other word modes than WordsPOSIX
+	words characterCounter
+
+	// word being read with WordsPOSIX, for the frequencies
+	word        []byte
+	frequencies *wordFrequencies
+	// widest segment of the current line and whether it has any character, for the histogram
+	lineWidth   int64
+	inLine      bool
+	lineLengths map[int64]int64
+
+	// text of the current line as UTF-8, without its newline, for the pattern & sloc
+	match       *regexp.Regexp
+	invert      bool
+	sloc        *slocCounter
+	content     contentCounter
+	readability *readabilityCounter
+	line        []byte
+
+	decoder   decoder
+	isUTF8    bool
+	linesOnly bool
+
+	// incomplete character left over at the end of the previous write
+	partial []byte
+
+	// what the first characters looked like, to stitch counters of consecutive chunks together
+	started         bool
+	startsInWord    bool
+	sawLineBreak    bool
+	firstLineLength int64
+	firstTabColumn  int64 // column of the first tab before any line break, -1 without one
+}
+
+// NewCounter returns a Counter with all the counts set to zero
+func NewCounter(options CountOptions) *Counter {
+	counter := &Counter{
+		decoder:        newDecoder(options.Encoding),
+		isUTF8:         options.Encoding == "" || options.Encoding == UTF8,
+		partial:        make([]byte, 0, maxCharSize),
+		firstTabColumn: -1,
+	}
+	var emit func(word []byte)
+	if options.WordFrequencies {
+		counter.frequencies = newWordFrequencies(options.FoldCase, options.StopWords)
+		emit = counter.frequencies.add
+	}
+	counter.words = newWordCounter(options.Words, emit)
+	if options.LineHistogram {
+		counter.lineLengths = map[int64]int64{}
+	}
+	counter.match, counter.invert = options.Match, options.Invert
+	if options.SLOC {
+		counter.sloc = &slocCounter{language: options.Language}
+	}
+	counter.content = newContentCounter(options.Content)
+	if options.Readability {
+		counter.readability = &readabilityCounter{}
+	}
+	switch options.Encoding {
+	case UTF16, UTF16LE, UTF16BE:
+	default:
+		counter.linesOnly = options.LinesOnly
+	}
+	if options.Graphemes {
+		counter.graphemes = newGraphemeCounter()
+	}
+	return counter
+}
+
+// Write counts the bytes of p, carrying a multi-byte character split across writes over to the next one
+func (c *Counter) Write(p []byte) (int, error) {
+	written := len(p)
+	c.counts.Bytes += int64(written)
+	if c.linesOnly {
+		c.counts.Lines += int64(bytes.Count(p, []byte{'\n'}))
+		return written, nil
+	}
+
+	// finish the character started by the previous write first
+	if n := len(c.partial); n > 0 {
+		head := append(c.partial, p[:min(len(p), maxCharSize)]...)
+		i := 0
+		for i < n {
+			size := c.decode(head[i:], false)
+			if size == 0 {
+				// p was too short to complete it, wait for the next write
+				c.partial = append(c.partial[:0], head[i:]...)
+				return written, nil
+			}
+			i += size
+		}
+		p = p[i-n:]
+		c.partial = c.partial[:0]
+	}
+
+	for i := 0; i < len(p); {
+		// fast path for ASCII
+		if c.isUTF8 && p[i] < utf8.RuneSelf {
+			c.add(rune(p[i]))
+			i++
+			continue
+		}
+		size := c.decode(p[i:], false)
+		if size == 0 {
+			c.partial = append(c.partial, p[i:]...)
+			break
+		}
+		i += size
+	}
+
+	return written, nil
+}
+
+// decode counts the first character of p and returns its size, 0 if p only holds the start of one
+func (c *Counter) decode(p []byte, atEOF bool) int {
+	r, size, valid := c.decoder.decode(p, atEOF)
+	switch {
+	case size == 0 || r == noCharacter:
+	case !valid:
+		c.counts.Invalid++
+	default:
+		c.add(r)
+	}
+	return size
+}
+
+// add updates the counts with a single decoded character
+func (c *Counter) add(r rune) {
+	c.counts.Characters++
+	if c.graphemes != nil {
+		c.graphemes.add(r)
+	}
+	if c.content != nil {
+		c.content.add(r)
+	}
+	if c.readability != nil {
+		c.readability.add(r)
+	}
+
+	if !c.started {
+		c.started = true
+		c.startsInWord = !unicode.IsSpace(r)
+	}
+
+	switch r {
+	case '\n':
+		c.counts.Lines++
+		c.endLine()
+		c.recordLine()
+	case '\r', '\f':
+		// like GNU wc -L, a carriage return or form feed starts over at the first column
+		c.endLine()
+	case '\t':
+		if !c.sawLineBreak && c.firstTabColumn < 0 {
+			c.firstTabColumn = c.lineLength
+		}
+		c.lineLength = nextTabStop(c.lineLength)
+	default:
+		c.lineLength += runeWidth(r)
+	}
+	if r != '\n' {
+		c.inLine = true
+		if c.match != nil || c.sloc != nil || c.content != nil {
+			c.line = utf8.AppendRune(c.line, r)
+		}
+	}
+
+	switch {
+	case c.words != nil:
+		c.words.add(r)
+	case unicode.IsSpace(r):
+		c.inWord = false
+		c.endWord()
+	default:
+		if !c.inWord {
+			c.counts.Words++
+			c.inWord = true
+		}
+		if c.frequencies != nil {
+			c.word = utf8.AppendRune(c.word, r)
+		}
+	}
+}
+
+// endWord counts the frequency of the word just read with WordsPOSIX, without
+// its leading & trailing punctuation so "end." and "end" are the same word
+func (c *Counter) endWord() {
+	if c.frequencies != nil && len(c.word) > 0 {
+		if word := bytes.TrimFunc(c.word, unicode.IsPunct); len(word) > 0 {
+			c.frequencies.add(word)
+		}
+		c.word = c.word[:0]
+	}
+}
+
+// endLine records the width of the current line
+func (c *Counter) endLine() {
+	if !c.sawLineBreak {
+		c.sawLineBreak = true
+		c.firstLineLength = c.lineLength
+	}
+	if c.lineLength > c.counts.MaxLineLength {
+		c.counts.MaxLineLength = c.lineLength
+	}
+	c.lineWidth = max(c.lineWidth, c.lineLength)
+	c.lineLength = 0
+}
+
+// recordLine adds the line just ended to the histogram & matches it against the pattern
+func (c *Counter) recordLine() {
+	if c.lineLengths != nil {
+		c.lineLengths[c.lineWidth]++
+	}
+	if c.match != nil {
+		c.matchLine()
+	}
+	if c.sloc != nil {
+		c.sloc.line(c.line)
+	}
+	if c.content != nil {
+		c.content.line(c.line)
+	}
+	c.line = c.line[:0]
+	c.lineWidth, c.inLine = 0, false
+}
+
+// matchLine counts the occurrences of the pattern in the line just ended,
+// leaving out the empty ones like `grep -o` does
+func (c *Counter) matchLine() {
+	matches := c.match.FindAllIndex(c.line, -1)
+	if (len(matches) > 0) != c.invert {
+		c.counts.MatchingLines++
+	}
+	for _, match := range matches {
+		if match[1] > match[0] {
+			c.counts.Matches++
+			c.counts.MatchedBytes += int64(match[1] - match[0])
+		}
+	}
+}
+
+// Counts returns the totals of everything written so far, once the input has ended
+func (c *Counter) Counts() Counts {
+	// a truncated character at the very end is made of invalid sequences
+	for i := 0; i < len(c.partial); {
+		i += c.decode(c.partial[i:], true)
+	}
+	c.partial = c.partial[:0]
+
+	// the last word & line may not be followed by a space or newline
+	if c.words != nil {
+		c.words.end()
+	}
+	c.endWord()
+	if c.inLine {
+		c.lineWidth = max(c.lineWidth, c.lineLength)
+		c.recordLine()
+	}
+
+	return c.Snapshot()
+}
+
+// Snapshot returns the totals of everything written so far while more may
+// still be written, leaving out an incomplete character at the end
+func (c *Counter) Snapshot() Counts {
+	// the last line may not end with a newline
+	counts := c.counts
+	if c.lineLength > counts.MaxLineLength {
+		counts.MaxLineLength = c.lineLength
+	}
+	if c.graphemes != nil {
+		counts.Graphemes = c.graphemes.total()
+	}
+	if c.words != nil {
+		counts.Words = c.words.total()
+	}
+	if c.frequencies != nil {
+		counts.WordFrequencies = c.frequencies.counts
+	}
+	counts.LineLengths = c.lineLengths
+	if c.sloc != nil {
+		c.sloc.counts(&counts)
+	}
+	if c.content != nil {
+		c.content.counts(&counts)
+	}
+	if c.readability != nil {
+		c.readability.counts(&counts)
+	}
+	return counts
+}
+
+// CountReader reads r until EOF and returns its counts. A compressed input
+// is decompressed first, see CountOptions.Compression.
+func CountReader(r io.Reader, options CountOptions) (Counts, error) {
+	if err := options.Range.check(options.Encoding); err != nil {
+		return Counts{}, err
+	}
+
+	compressed := &byteCounter{r: r, onRead: options.OnRead}
+	decompressed, closeReader, err := decompress(compressed, options.Compression)
+	if err != nil {
+		return Counts{}, err
+	}
+	defer closeReader()
+	if !options.Range.IsZero() {
+		decompressed = options.Range.reader(decompressed)
+	}
+
+	counter := NewCounter(options)
+	buffer := make([]byte, 64*1024)
+	if _, err := io.CopyBuffer(counter, decompressed, buffer); err != nil {
+		return counter.Counts(), fmt.Errorf("error reading input: %w", err)
+	}
+
+	counts := counter.Counts()
+	if options.CompressedBytes {
+		// the decompressor may leave trailing bytes of the input unread
+		if _, err := io.CopyBuffer(io.Discard, compressed, buffer); err != nil {
+			return counts, fmt.Errorf("error reading input: %w", err)
+		}
+		counts.Bytes = compressed.count
+	}
+	return counts, nil
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+// the expected counts are the ones of GNU wc -lwmcL in a UTF-8 locale
+var counterTests = []struct {
+	name  string
+	input string
+	want  Counts
+}{
+	{"empty", "", Counts{}},
+	{"trailing newline", "a b\n", Counts{Lines: 1, Words: 2, Characters: 4, Bytes: 4, MaxLineLength: 3}},
+	{"no trailing newline", "a b", Counts{Lines: 0, Words: 2, Characters: 3, Bytes: 3, MaxLineLength: 3}},
+	{"crlf", "a\r\nbc\r\n", Counts{Lines: 2, Words: 2, Characters: 7, Bytes: 7, MaxLineLength: 2}},
+	{"carriage return", "ab\rc\n", Counts{Lines: 1, Words: 2, Characters: 5, Bytes: 5, MaxLineLength: 2}},
+	{"invalid utf-8", "a\xffb\n", Counts{Lines: 1, Words: 1, Characters: 3, Bytes: 4, MaxLineLength: 2, Invalid: 1}},
+	{"multi-byte rune", "h\xc3\xa9\n", Counts{Lines: 1, Words: 1, Characters: 3, Bytes: 4, MaxLineLength: 2}},
+	{"tab", "\tx\n", Counts{Lines: 1, Words: 1, Characters: 3, Bytes: 3, MaxLineLength: 9}},
+	{"wide characters", "日本 語\n", Counts{Lines: 1, Words: 2, Characters: 5, Bytes: 11, MaxLineLength: 7}},
+}
+
+func TestCounter(t *testing.T) {
+	for _, test := range counterTests {
+		t.Run(test.name, func(t *testing.T) {
+			counter := NewCounter(CountOptions{})
+			counter.Write([]byte(test.input))
+			if got := counter.Counts(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Counts() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// a rune split across two writes is counted once, wherever the input is split
+func TestCounterSplitWrites(t *testing.T) {
+	for _, test := range counterTests {
+		for split := 1; split < len(test.input); split++ {
+			counter := NewCounter(CountOptions{})
+			counter.Write([]byte(test.input[:split]))
+			counter.Write([]byte(test.input[split:]))
+			if got := counter.Counts(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("%s, split at %d: Counts() = %+v, want %+v", test.name, split, got, test.want)
+			}
+		}
+	}
+}
+
+// LinesOnly only counts the lines & bytes, without decoding the characters
+func TestCounterLinesOnly(t *testing.T) {
+	for _, test := range counterTests {
+		t.Run(test.name, func(t *testing.T) {
+			counter := NewCounter(CountOptions{LinesOnly: true})
+			counter.Write([]byte(test.input))
+			got := counter.Counts()
+			if got.Lines != test.want.Lines || got.Bytes != test.want.Bytes {
+				t.Errorf("lines & bytes = %d %d, want %d %d", got.Lines, got.Bytes, test.want.Lines, test.want.Bytes)
+			}
+		})
+	}
+}
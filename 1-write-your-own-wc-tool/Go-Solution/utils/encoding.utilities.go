@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding names accepted by ParseEncoding
+type Encoding string
+
+const (
+	UTF8    Encoding = "utf-8"
+	UTF16   Encoding = "utf-16" // byte order taken from the BOM, big endian without one
+	UTF16LE Encoding = "utf-16le"
+	UTF16BE Encoding = "utf-16be"
+	Latin1  Encoding = "latin-1"
+)
+
+// Longest character, in bytes, of any supported encoding (a UTF-16 surrogate pair)
+const maxCharSize int = 4
+
+// Throwing the custom error for an unsupported encoding
+var ErrEncoding = errors.New("unsupported encoding, expected utf-8, utf-16, utf-16le, utf-16be or latin-1")
+
+// ParseEncoding returns the Encoding for a name, ignoring case and the usual aliases
+func ParseEncoding(name string) (Encoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "-")) {
+	case "", "utf-8", "utf8":
+		return UTF8, nil
+	case "utf-16", "utf16":
+		return UTF16, nil
+	case "utf-16le", "utf16le":
+		return UTF16LE, nil
+	case "utf-16be", "utf16be":
+		return UTF16BE, nil
+	case "latin-1", "latin1", "iso-8859-1", "iso8859-1":
+		return Latin1, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrEncoding, name)
+}
+
+// Rune returned for byte sequences that are neither characters nor invalid
+const noCharacter rune = -1
+
+// decoder splits a stream into characters
+type decoder interface {
+	// decode returns the first character of p and its size in bytes, with
+	// valid false for a sequence which isn't a character in the encoding.
+	// A size of 0 means p only holds the start of a character, which never
+	// happens atEOF, and a valid noCharacter is a mark like a BOM that isn't
+	// counted at all.
+	decode(p []byte, atEOF bool) (r rune, size int, valid bool)
+}
+
+// newDecoder returns a decoder for a single input in the encoding
+func newDecoder(encoding Encoding) decoder {
+	switch encoding {
+	case UTF16:
+		return &utf16Decoder{detectBOM: true}
+	case UTF16LE:
+		return &utf16Decoder{littleEndian: true}
+	case UTF16BE:
+		return &utf16Decoder{}
+	case Latin1:
+		return latin1Decoder{}
+	}
+	return utf8Decoder{}
+}
+
+type utf8Decoder struct{}
+
+func (utf8Decoder) decode(p []byte, atEOF bool) (rune, int, bool) {
+	if p[0] < utf8.RuneSelf {
+		return rune(p[0]), 1, true
+	}
+	if !atEOF && !utf8.FullRune(p) {
+		return 0, 0, false
+	}
+	r, size := utf8.DecodeRune(p)
+	return r, size, r != utf8.RuneError || size > 1
+}
+
+// every byte of ISO-8859-1 is the code point of the same value
+type latin1Decoder struct{}
+
+func (latin1Decoder) decode(p []byte, atEOF bool) (rune, int, bool) {
+	return rune(p[0]), 1, true
+}
+
+type utf16Decoder struct {
+	littleEndian bool
+	// look for a byte order mark at the start of the input, which isn't counted as a character
+	detectBOM bool
+}
+
+func (d *utf16Decoder) unit(p []byte) rune {
+	if d.littleEndian {
+		return rune(p[0]) | rune(p[1])<<8
+	}
+	return rune(p[0])<<8 | rune(p[1])
+}
+
+func (d *utf16Decoder) decode(p []byte, atEOF bool) (rune, int, bool) {
+	if len(p) < 2 {
+		if atEOF {
+			return utf8.RuneError, len(p), false
+		}
+		return 0, 0, false
+	}
+
+	if d.detectBOM {
+		d.detectBOM = false
+		switch {
+		case p[0] == 0xFF && p[1] == 0xFE:
+			d.littleEndian = true
+			return noCharacter, 2, true
+		case p[0] == 0xFE && p[1] == 0xFF:
+			return noCharacter, 2, true
+		}
+	}
+
+	first := d.unit(p)
+	if !utf16.IsSurrogate(first) {
+		return first, 2, true
+	}
+	// a high surrogate has to be followed by a low one
+	if first >= 0xDC00 {
+		return utf8.RuneError, 2, false
+	}
+	if len(p) < 4 {
+		if atEOF {
+			return utf8.RuneError, 2, false
+		}
+		return 0, 0, false
+	}
+	r := utf16.DecodeRune(first, d.unit(p[2:]))
+	if r == utf8.RuneError {
+		return r, 2, false
+	}
+	return r, 4, true
+}
@@ -1,133 +1,134 @@
 package utils
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"net/http"
 	"os"
+	"time"
 )
 
-func GetFileInformation(filename string) (string, error) {
-	fileInfo, err := os.Stat(filename)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// prepate the return message
-	message := fmt.Sprint("\nInformation",
-		"\n File Name: ", fileInfo.Name(),
-		"\n Permissions: ", fileInfo.Mode(),
-		"\n Last Modified: ", fileInfo.ModTime(),
-	)
-
-	// return
-	return message, err
+// FileInformation holds the metadata of a file shown alongside its counts
+type FileInformation struct {
+	Name         string
+	Permissions  fs.FileMode
+	LastModified time.Time
+	Size         int64
+
+	// owner & group names, or their ids when they have no name
+	Owner string
+	Group string
+	Inode uint64
+	Links uint64
+
+	// what the name points to when it is a symbolic link, the other fields describe the target
+	SymlinkTarget string
+
+	// detected from the first bytes of a regular file, like net/http.DetectContentType does
+	MIMEType string
+	// whether the first BinarySniffSize bytes of a regular file have a NUL byte
+	Binary bool
 }
 
-func GetFileSize(filename string) (string, error) {
+// GetFileInformation returns the metadata of the named file. When part of it
+// can't be read, like the MIME type of an unreadable file, the fields read
+// before are returned along with the error.
+func GetFileInformation(filename string) (FileInformation, error) {
 	fileInfo, err := os.Stat(filename)
 	if err != nil {
-		log.Fatal(err)
+		return FileInformation{}, fmt.Errorf("error reading file information: %w", err)
 	}
-	// prepate the return message
-	message := fmt.Sprintf("\n Size (in bytes): %d", fileInfo.Size())
-
-	// return
-	return message, err
-}
 
-func GetFileLines(filename string) (string, error) {
-	// initiate file-hanndle to read from
-	file, err := os.Open(filename)
-
-	// check if file-handle was initiated correctly
-	if err != nil {
-		log.Fatal(err)
+	information := FileInformation{
+		Name:         fileInfo.Name(),
+		Permissions:  fileInfo.Mode(),
+		LastModified: fileInfo.ModTime(),
+		Size:         fileInfo.Size(),
 	}
+	information.Owner, information.Group, information.Inode, information.Links = ownership(fileInfo)
 
-	// initiate scanner from file-handle
-	fileScanner := bufio.NewScanner(file)
-
-	// tell the scanner to split by lines
-	fileScanner.Split(bufio.ScanLines)
-
-	// initiate the counter & looping through lines
-	lineCount := 0
-	for fileScanner.Scan() {
-		lineCount += 1
+	// the link itself, when the name is one
+	if linkInfo, err := os.Lstat(filename); err == nil && linkInfo.Mode()&fs.ModeSymlink != 0 {
+		if information.SymlinkTarget, err = os.Readlink(filename); err != nil {
+			return information, fmt.Errorf("error reading file information: %w", err)
+		}
 	}
 
-	// make sure to close the file-hanlde upon return
-	defer file.Close()
-
-	// prepate the return message
-	message := fmt.Sprintf("\n Total lines: %d", lineCount)
+	if fileInfo.Mode().IsRegular() {
+		header, err := readHeader(filename, BinarySniffSize)
+		if err != nil {
+			return information, err
+		}
+		information.MIMEType = http.DetectContentType(header)
+		information.Binary = isBinary(header)
+	}
 
 	// return
-	return message, err
+	return information, nil
 }
 
-func GetFileCharacters(filename string) (string, error) {
-	// initiate the file-hanndle to read from
+// readHeader returns up to the first size bytes of the named file
+func readHeader(filename string, size int) ([]byte, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	// initiate the scanner from file-handle
-	fileScanner := bufio.NewScanner(file)
-
-	// tell the scanner to split the chars
-	fileScanner.Split(bufio.ScanRunes)
-
-	// initiate the counter & looping through the runes(ASCII) / character
-	charCount := 0
-	for fileScanner.Scan() {
-		charCount += 1
+		return nil, fmt.Errorf("error opening file: %w", err)
 	}
-
-	// make sure to close the file-handle upon return
 	defer file.Close()
 
-	// prepate the return message
-	message := fmt.Sprintf("\n Total characters: %d", charCount)
-
-	// return
-	return message, err
+	header := make([]byte, size)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("error reading input: %w", err)
+	}
+	return header[:n], nil
 }
 
-func GetFileWords(filename string) (string, error) {
-	// initiate file-handle to read from
-	file, err := os.Open(filename)
-
-	// check if file-handle was initiated correctly
+// ReadFileList returns the file names of a list separated by separator,
+// like the NUL separated output of `find -print0` or one name per line.
+// An empty name in a NUL separated list is kept, so it can be reported.
+func ReadFileList(r io.Reader, separator byte) ([]string, error) {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error reading file list: %w", err)
 	}
 
-	// initiate scanner from file-handle
-	fileScanner := bufio.NewScanner(file)
-
-	// tell the scanner to split by words
-	fileScanner.Split(bufio.ScanWords)
-
-	// initiate counter & looping through words
-	wordCount := 0
-	for fileScanner.Scan() {
-		wordCount += 1
+	var names []string
+	for _, name := range bytes.Split(data, []byte{separator}) {
+		if separator == '\n' {
+			// one name per line, ignoring blank lines & Windows line endings
+			name = bytes.TrimSuffix(name, []byte{'\r'})
+			if len(name) == 0 {
+				continue
+			}
+		}
+		names = append(names, string(name))
 	}
 
-	// check if there was error while reading words from file
-	if err := fileScanner.Err(); err != nil {
-		log.Fatal(err)
+	// the list normally ends with a separator, which doesn't start another name
+	if len(names) > 0 && names[len(names)-1] == "" {
+		names = names[:len(names)-1]
 	}
+	return names, nil
+}
 
-	// make to close file-handle upon return
-	defer file.Close()
+func GetFileSize(file io.Reader) (int64, error) {
+	counts, err := CountReader(file, CountOptions{LinesOnly: true})
+	return counts.Bytes, err
+}
 
-	// prepate the return message
-	message := fmt.Sprintf("\n Total words: %d", wordCount)
+func GetFileLines(file io.Reader) (int64, error) {
+	counts, err := CountReader(file, CountOptions{LinesOnly: true})
+	return counts.Lines, err
+}
 
-	// return
-	return message, err
+func GetFileCharacters(file io.Reader) (int64, error) {
+	counts, err := CountReader(file, CountOptions{})
+	return counts.Characters, err
+}
+
+func GetFileWords(file io.Reader) (int64, error) {
+	counts, err := CountReader(file, CountOptions{})
+	return counts.Words, err
 }
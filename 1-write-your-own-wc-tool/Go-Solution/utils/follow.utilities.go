@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// Follower counts a file while it grows, like `tail -F`: every Poll counts
+// the bytes appended since the previous one. When the file is truncated, or
+// replaced by another file of the same name by a log rotation, it is counted
+// again from its start. The file is counted as it is, options.Compression
+// is ignored.
+type Follower struct {
+	filename string
+	options  CountOptions
+	file     *os.File
+	info     fs.FileInfo
+	offset   int64
+	counter  *Counter
+	buffer   []byte
+}
+
+// NewFollower opens the named file to follow it, nothing is counted before the first Poll
+func NewFollower(filename string, options CountOptions) (*Follower, error) {
+	f := &Follower{filename: filename, options: options, buffer: make([]byte, 64*1024)}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Follower) open() error {
+	file, err := os.Open(f.filename)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error reading file information: %w", err)
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file, f.info, f.offset, f.counter = file, info, 0, NewCounter(f.options)
+	return nil
+}
+
+// Poll counts what was appended since the last call and returns the counts of
+// the whole file so far. reset is true when the file was truncated or
+// replaced, and the counts started over.
+func (f *Follower) Poll() (counts Counts, reset bool, err error) {
+	// a new file under the same name, the old one was rotated away; while
+	// there is no new file yet, the old one is still followed
+	if info, err := os.Stat(f.filename); err == nil && !os.SameFile(info, f.info) {
+		if err := f.open(); err != nil {
+			return f.counter.Snapshot(), false, err
+		}
+		reset = true
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return f.counter.Snapshot(), false, fmt.Errorf("error reading file information: %w", err)
+	}
+
+	info, err := f.file.Stat()
+	if err != nil {
+		return f.counter.Snapshot(), reset, fmt.Errorf("error reading file information: %w", err)
+	}
+	if info.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return f.counter.Snapshot(), reset, fmt.Errorf("error reading input: %w", err)
+		}
+		f.offset, f.counter = 0, NewCounter(f.options)
+		reset = true
+	}
+
+	n, err := io.CopyBuffer(f.counter, f.file, f.buffer)
+	f.offset += n
+	if err != nil {
+		return f.counter.Snapshot(), reset, fmt.Errorf("error reading input: %w", err)
+	}
+	return f.counter.Snapshot(), reset, nil
+}
+
+// Close closes the followed file
+func (f *Follower) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"golang.org/x/text/cases"
+)
+
+// Common English words left out of the word frequencies with --stop-words english
+var EnglishStopWords = []string{
+	"a", "about", "after", "all", "also", "an", "and", "any", "are", "as", "at",
+	"be", "because", "been", "but", "by", "can", "could", "do", "does", "for",
+	"from", "had", "has", "have", "he", "her", "his", "how", "i", "if", "in",
+	"into", "is", "it", "its", "just", "me", "more", "my", "no", "not", "of",
+	"on", "one", "only", "or", "other", "our", "out", "she", "so", "some",
+	"than", "that", "the", "their", "them", "then", "there", "these", "they",
+	"this", "to", "up", "us", "was", "we", "were", "what", "when", "which",
+	"who", "will", "with", "would", "you", "your",
+}
+
+// ReadStopWords returns the words of a stop word list, separated by white space
+func ReadStopWords(r io.Reader) ([]string, error) {
+	var words []string
+	wordScanner := bufio.NewScanner(r)
+	wordScanner.Split(bufio.ScanWords)
+	for wordScanner.Scan() {
+		words = append(words, wordScanner.Text())
+	}
+	if err := wordScanner.Err(); err != nil {
+		return words, fmt.Errorf("error reading stop words: %w", err)
+	}
+	return words, nil
+}
+
+// wordFrequencies counts how often every word occurs
+type wordFrequencies struct {
+	counts   map[string]int64
+	foldCase bool
+	fold     cases.Caser
+	// folded stop words, which aren't counted whatever their case
+	stopWords map[string]bool
+}
+
+func newWordFrequencies(foldCase bool, stopWords []string) *wordFrequencies {
+	f := &wordFrequencies{counts: map[string]int64{}, foldCase: foldCase, fold: cases.Fold(), stopWords: map[string]bool{}}
+	for _, word := range stopWords {
+		f.stopWords[f.fold.String(word)] = true
+	}
+	return f
+}
+
+func (f *wordFrequencies) add(word []byte) {
+	key := string(word)
+	if f.foldCase {
+		key = f.fold.String(key)
+	}
+	if len(f.stopWords) > 0 && f.stopWords[f.fold.String(key)] {
+		return
+	}
+	f.counts[key]++
+}
+
+// WordCount is a word with the number of times it occurs
+type WordCount struct {
+	Word  string
+	Count int64
+}
+
+// TopWords returns the n most frequent words, the most frequent first and
+// alphabetically for the same count. Every word is returned for n <= 0.
+func TopWords(frequencies map[string]int64, n int) []WordCount {
+	words := make([]WordCount, 0, len(frequencies))
+	for word, count := range frequencies {
+		words = append(words, WordCount{Word: word, Count: count})
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Word < words[j].Word
+	})
+	if n > 0 && len(words) > n {
+		words = words[:n]
+	}
+	return words
+}
+
+// HistogramBucket is the number of lines whose length is between From & To, both included
+type HistogramBucket struct {
+	From  int64
+	To    int64
+	Lines int64
+}
+
+// Histogram groups the number of lines of every length into buckets of size
+// lengths each, shortest first. Only the buckets with lines are returned, so a
+// single very long line doesn't add a bucket for every length below it.
+func Histogram(lineLengths map[int64]int64, size int64) []HistogramBucket {
+	lines := map[int64]int64{}
+	for length, n := range lineLengths {
+		lines[length/size] += n
+	}
+
+	buckets := make([]HistogramBucket, 0, len(lines))
+	for i, n := range lines {
+		buckets = append(buckets, HistogramBucket{From: i * size, To: i*size + size - 1, Lines: n})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].From < buckets[j].From })
+	return buckets
+}
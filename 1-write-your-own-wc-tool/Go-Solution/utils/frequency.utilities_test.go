@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWordFrequencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		options CountOptions
+		input   string
+		want    map[string]int64
+	}{
+		{"case kept", CountOptions{}, "The cat saw the CAT\n", map[string]int64{"The": 1, "cat": 1, "saw": 1, "the": 1, "CAT": 1}},
+		{"case folded", CountOptions{FoldCase: true}, "The cat saw the CAT\n", map[string]int64{"the": 2, "cat": 2, "saw": 1}},
+		{"stop words whatever their case", CountOptions{StopWords: []string{"THE", "a"}}, "The cat saw the A dog\n", map[string]int64{"cat": 1, "saw": 1, "dog": 1}},
+		{"stop words & folded case", CountOptions{FoldCase: true, StopWords: EnglishStopWords}, "It was the Dog, the dog!\n", map[string]int64{"dog": 2}},
+		{"punctuation around posix words", CountOptions{}, "(end) end. \"end\" end's -- ...\n", map[string]int64{"end": 3, "end's": 1}},
+		{"unicode words", CountOptions{Words: WordsUnicode, FoldCase: true}, "Hello, world. HELLO!\n", map[string]int64{"hello": 2, "world": 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := test.options
+			options.WordFrequencies = true
+			counts, err := CountReader(strings.NewReader(test.input), options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(counts.WordFrequencies, test.want) {
+				t.Errorf("WordFrequencies = %v, want %v", counts.WordFrequencies, test.want)
+			}
+		})
+	}
+}
+
+func TestTopWords(t *testing.T) {
+	frequencies := map[string]int64{"b": 2, "a": 2, "c": 5, "d": 1}
+	want := []WordCount{{"c", 5}, {"a", 2}, {"b", 2}}
+	if got := TopWords(frequencies, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("TopWords = %v, want %v", got, want)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name        string
+		lineLengths map[int64]int64
+		want        []HistogramBucket
+	}{
+		{"no lines", nil, []HistogramBucket{}},
+		{"same bucket", map[int64]int64{0: 2, 9: 1}, []HistogramBucket{{0, 9, 3}}},
+		{"empty buckets left out", map[int64]int64{3: 1, 10: 2, 1000000: 1}, []HistogramBucket{{0, 9, 1}, {10, 19, 2}, {1000000, 1000009, 1}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Histogram(test.lineLengths, 10); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Histogram = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
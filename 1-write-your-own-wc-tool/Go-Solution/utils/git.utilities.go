@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Throwing the custom error when git can't read the repository
+var ErrGit = errors.New("git failed")
+
+// GitStatus tells how a file changed between two revisions
+type GitStatus byte
+
+const (
+	GitAdded    GitStatus = 'A'
+	GitDeleted  GitStatus = 'D'
+	GitModified GitStatus = 'M'
+)
+
+// GitChange is a file which differs between two revisions
+type GitChange struct {
+	Path   string
+	Status GitStatus
+}
+
+// git runs git in the current directory and returns its standard output
+func git(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, gitError(err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// gitError wraps ErrGit with the message git wrote, or with err when there is none
+func gitError(err error, stderr string) error {
+	if message := strings.TrimSpace(stderr); message != "" {
+		// git prefixes its messages with "fatal: ", "error: "...
+		if _, text, ok := strings.Cut(message, ": "); ok && !strings.Contains(message, "\n") {
+			message = text
+		}
+		return fmt.Errorf("%w: %s", ErrGit, message)
+	}
+	return fmt.Errorf("%w: %v", ErrGit, err)
+}
+
+// GitFiles returns the files tracked at the revision rev below paths, all the
+// files below the current directory when there are none, with their names
+// relative to the current directory. Submodules are left out.
+func GitFiles(ctx context.Context, rev string, paths []string) ([]string, error) {
+	output, err := git(ctx, append([]string{"ls-tree", "-r", "-z", rev, "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// <mode> SP <type> SP <object> TAB <path>
+		info, path, ok := strings.Cut(entry, "\t")
+		if !ok || len(strings.Fields(info)) != 3 || strings.Fields(info)[1] != "blob" {
+			continue
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// GitChanges returns the files below paths which differ between the
+// revisions from & to, or between from & the working tree when to is
+// empty, with their names relative to the current directory. Renamed files
+// are reported as deleted & added.
+func GitChanges(ctx context.Context, from string, to string, paths []string) ([]GitChange, error) {
+	args := []string{"diff", "--relative", "--no-renames", "--no-ext-diff", "--name-status", "-z", from}
+	if to != "" {
+		args = append(args, to)
+	}
+	output, err := git(ctx, append(append(args, "--"), paths...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// <status> NUL <path> NUL, for every file
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	var changes []GitChange
+	for i := 0; i+1 < len(fields); i += 2 {
+		status := GitModified
+		switch fields[i] {
+		case "A":
+			status = GitAdded
+		case "D":
+			status = GitDeleted
+		}
+		changes = append(changes, GitChange{Path: fields[i+1], Status: status})
+	}
+	return changes, nil
+}
+
+// OpenGitFile returns the contents of the named file as it is at the revision
+// rev, read from the object database of the repository. Reading it fails if
+// the file doesn't exist at rev.
+func OpenGitFile(ctx context.Context, rev string, name string) (io.ReadCloser, error) {
+	// "./" makes the name relative to the current directory, rather than to the top of the repository
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "blob", rev+":./"+name)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGit, err)
+	}
+	file := &gitFile{cmd: cmd, stdout: stdout}
+	cmd.Stderr = &file.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGit, err)
+	}
+	return file, nil
+}
+
+// gitFile reads the output of `git cat-file`, the error of git is returned at its end
+type gitFile struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+	done   bool
+	err    error
+}
+
+func (g *gitFile) Read(p []byte) (int, error) {
+	if g.done {
+		return 0, g.end()
+	}
+	n, err := g.stdout.Read(p)
+	if err == io.EOF {
+		g.done = true
+		if end := g.end(); end != io.EOF {
+			return n, end
+		}
+	}
+	return n, err
+}
+
+// end waits for git once all its output was read, and returns io.EOF when it succeeded
+func (g *gitFile) end() error {
+	if g.err == nil {
+		g.err = io.EOF
+		if err := g.cmd.Wait(); err != nil {
+			g.err = gitError(err, g.stderr.String())
+		}
+	}
+	return g.err
+}
+
+func (g *gitFile) Close() error {
+	if g.err != nil {
+		return nil
+	}
+	// git is stopped when the rest of the file isn't needed
+	if !g.done {
+		g.cmd.Process.Kill()
+	}
+	g.stdout.Close()
+	g.cmd.Wait()
+	g.err = io.EOF
+	return nil
+}
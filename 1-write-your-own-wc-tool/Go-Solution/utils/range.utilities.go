@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Range selects the part of an input which is counted: the Length bytes
+// from byte Offset, and then the lines FromLine to ToLine of those, counted
+// from 1 and both included. Zero values select the whole input, from its
+// start or up to its end.
+type Range struct {
+	Offset int64
+	Length int64
+
+	FromLine int64
+	ToLine   int64
+}
+
+// Throwing the custom error when lines are selected in UTF-16, where a newline isn't a single byte
+var ErrLineRangeEncoding = errors.New("line ranges aren't available in UTF-16")
+
+// IsZero reports whether the whole input is selected
+func (r Range) IsZero() bool {
+	return r == Range{}
+}
+
+// lines reports whether lines are selected
+func (r Range) lines() bool {
+	return r.FromLine > 1 || r.ToLine > 0
+}
+
+// check returns ErrLineRangeEncoding when lines are selected in UTF-16
+func (r Range) check(encoding Encoding) error {
+	switch encoding {
+	case UTF16, UTF16LE, UTF16BE:
+		if r.lines() {
+			return ErrLineRangeEncoding
+		}
+	}
+	return nil
+}
+
+// section returns the offset & length of the selected bytes of the first size
+// bytes of in: the byte range is sought directly, the lines are found by
+// looking for newline bytes without decoding the characters
+func (r Range) section(in io.ReaderAt, size int64) (int64, int64, error) {
+	start, end := min(r.Offset, size), size
+	if r.Length > 0 {
+		end = min(start+r.Length, size)
+	}
+	if !r.lines() {
+		return start, end - start, nil
+	}
+
+	buffer := make([]byte, 64*1024)
+	line, offset, from := int64(1), start, start
+	if r.FromLine > 1 {
+		from = end
+	}
+	for offset < end && (line < r.FromLine || r.ToLine > 0) {
+		n, err := in.ReadAt(buffer[:min(int64(len(buffer)), end-offset)], offset)
+		if err != nil && err != io.EOF {
+			return 0, 0, fmt.Errorf("error reading input: %w", err)
+		}
+		if n == 0 {
+			break
+		}
+		block := buffer[:n]
+		for {
+			i := bytes.IndexByte(block, '\n')
+			if i < 0 {
+				break
+			}
+			newline := offset + int64(n-len(block)+i)
+			if line == r.FromLine-1 {
+				from = newline + 1
+			}
+			if line == r.ToLine {
+				return from, max(newline+1-from, 0), nil
+			}
+			line++
+			block = block[i+1:]
+		}
+		offset += int64(n)
+		if line >= r.FromLine && r.ToLine == 0 {
+			break
+		}
+	}
+	return from, max(end-from, 0), nil
+}
+
+// reader returns the selected bytes of in, for inputs which can only be read
+// from their start: the bytes before the range are read & skipped, and
+// reading stops at the end of the range
+func (r Range) reader(in io.Reader) io.Reader {
+	if r.Offset > 0 || r.Length > 0 {
+		in = &byteRangeReader{r: in, skip: r.Offset, left: r.Length, limited: r.Length > 0}
+	}
+	if r.lines() {
+		in = &lineRangeReader{r: in, line: 1, from: r.FromLine, to: r.ToLine}
+	}
+	return in
+}
+
+// byteRangeReader skips the first skip bytes of r, then reads at most left bytes when limited
+type byteRangeReader struct {
+	r       io.Reader
+	skip    int64
+	left    int64
+	limited bool
+}
+
+func (b *byteRangeReader) Read(p []byte) (int, error) {
+	if b.skip > 0 {
+		skip := b.skip
+		b.skip = 0
+		if _, err := io.CopyN(io.Discard, b.r, skip); err != nil {
+			return 0, err
+		}
+	}
+	if b.limited {
+		if b.left <= 0 {
+			return 0, io.EOF
+		}
+		p = p[:min(int64(len(p)), b.left)]
+	}
+	n, err := b.r.Read(p)
+	b.left -= int64(n)
+	return n, err
+}
+
+// lineRangeReader skips the lines of r before line from, and stops reading after line to when it isn't 0
+type lineRangeReader struct {
+	r        io.Reader
+	line     int64
+	from, to int64
+}
+
+func (l *lineRangeReader) Read(p []byte) (int, error) {
+	for {
+		if l.to > 0 && l.line > l.to {
+			return 0, io.EOF
+		}
+		n, err := l.r.Read(p)
+		block := p[:n]
+
+		// drop the lines before the range
+		for l.line < l.from && len(block) > 0 {
+			i := bytes.IndexByte(block, '\n')
+			if i < 0 {
+				block = block[:0]
+				break
+			}
+			block = block[i+1:]
+			l.line++
+		}
+		// a range starting after its last line selects nothing, like the sought one
+		if l.to > 0 && l.line > l.to {
+			return 0, io.EOF
+		}
+
+		// and stop after its last line
+		selected := len(block)
+		for i := 0; l.to > 0 && i < len(block); {
+			j := bytes.IndexByte(block[i:], '\n')
+			if j < 0 {
+				break
+			}
+			i += j + 1
+			if l.line++; l.line > l.to {
+				selected = i
+				break
+			}
+		}
+
+		n = copy(p, block[:selected])
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
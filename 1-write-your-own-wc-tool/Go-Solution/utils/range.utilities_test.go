@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// seeking a range selects the same bytes as reading the input up to it
+func TestRangeSectionMatchesReader(t *testing.T) {
+	inputs := []string{
+		"",
+		"no newline",
+		"one\ntwo\nthree\n",
+		"one\ntwo\nthree",
+		"\n\n\na\n\n",
+		"a\r\nb\r\nc\r\n",
+	}
+	ranges := []Range{
+		{Offset: 2},
+		{Length: 5},
+		{Offset: 3, Length: 4},
+		{Offset: 100},
+		{FromLine: 2},
+		{ToLine: 2},
+		{FromLine: 2, ToLine: 2},
+		{FromLine: 3, ToLine: 1},
+		{FromLine: 10},
+		{Offset: 4, FromLine: 2},
+		{Offset: 1, Length: 9, ToLine: 2},
+		{Offset: 5, Length: 2, FromLine: 1, ToLine: 1},
+	}
+
+	for _, input := range inputs {
+		for _, r := range ranges {
+			name := fmt.Sprintf("%q %+v", input, r)
+			offset, length, err := r.section(bytes.NewReader([]byte(input)), int64(len(input)))
+			if err != nil {
+				t.Fatalf("%s: section: %v", name, err)
+			}
+			sought := input[offset : offset+length]
+
+			read, err := io.ReadAll(r.reader(bytes.NewReader([]byte(input))))
+			if err != nil {
+				t.Fatalf("%s: reader: %v", name, err)
+			}
+			if sought != string(read) {
+				t.Errorf("%s: section = %q, reader = %q", name, sought, read)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package utils
+
+import "unicode"
+
+// readabilityCounter counts the sentences, paragraphs & syllables of prose
+// for the Flesch scores. A sentence ends with '.', '!' or '?' followed by
+// white space, or with its paragraph; a paragraph is a block of lines
+// between blank lines. Syllables are estimated from the vowel groups of
+// every word, as English.
+type readabilityCounter struct {
+	sentences  int64
+	paragraphs int64
+	syllables  int64
+
+	// whether the current sentence has a word, and ends if white space follows
+	inSentence  bool
+	sentenceEnd bool
+	inParagraph bool
+	lineHasText bool
+	// letters of the current word, lowercased
+	word []rune
+}
+
+func (r *readabilityCounter) add(c rune) {
+	switch {
+	case c == '\n':
+		r.endWord()
+		r.endSentence(r.sentenceEnd || !r.lineHasText)
+		// a blank line ends the paragraph
+		if !r.lineHasText {
+			r.inParagraph = false
+		}
+		r.lineHasText = false
+	case unicode.IsSpace(c):
+		r.endWord()
+		r.endSentence(r.sentenceEnd)
+	default:
+		if !r.inParagraph {
+			r.paragraphs++
+			r.inParagraph = true
+		}
+		r.lineHasText = true
+
+		switch {
+		case isSentenceEnd(c):
+			r.endWord()
+			r.sentenceEnd = r.inSentence
+		case unicode.IsLetter(c) || c == '\'' || c == '’':
+			r.word = append(r.word, unicode.ToLower(c))
+			r.inSentence = true
+			r.sentenceEnd = false
+		case unicode.IsNumber(c):
+			r.inSentence = true
+			r.sentenceEnd = false
+		case isClosing(c):
+			// "Stop." she said, the quote closes the sentence
+		default:
+			r.endWord()
+			r.sentenceEnd = false
+		}
+	}
+}
+
+func (r *readabilityCounter) endWord() {
+	r.syllables += syllables(r.word)
+	r.word = r.word[:0]
+}
+
+// endSentence counts the current sentence when it has ended
+func (r *readabilityCounter) endSentence(ended bool) {
+	if ended && r.inSentence {
+		r.sentences++
+		r.inSentence = false
+	}
+	r.sentenceEnd = false
+}
+
+// counts sets the readability counts so far, the last sentence may not have ended with a period
+func (r *readabilityCounter) counts(counts *Counts) {
+	counts.Sentences, counts.TextParagraphs, counts.Syllables = r.sentences, r.paragraphs, r.syllables+syllables(r.word)
+	if r.inSentence {
+		counts.Sentences++
+	}
+}
+
+func isSentenceEnd(c rune) bool {
+	switch c {
+	case '.', '!', '?', '…', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+func isClosing(c rune) bool {
+	switch c {
+	case '"', ')', ']', '”', '»':
+		return true
+	}
+	return false
+}
+
+// syllables estimates the syllables of an English word from its groups of
+// vowels, leaving out a silent e at the end. A word has at least one.
+func syllables(word []rune) int64 {
+	var count int64
+	vowel := false
+	letters := 0
+	for _, c := range word {
+		if !unicode.IsLetter(c) {
+			continue
+		}
+		letters++
+		isVowel := isVowel(c)
+		if isVowel && !vowel {
+			count++
+		}
+		vowel = isVowel
+	}
+	if letters == 0 {
+		return 0
+	}
+
+	// "make" has one syllable, but "table" & "see" have their last e
+	if n := len(word); count > 1 && n > 2 && word[n-1] == 'e' && word[n-2] != 'l' && !isVowel(word[n-2]) {
+		count--
+	}
+	return max(count, 1)
+}
+
+func isVowel(c rune) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y', 'à', 'á', 'â', 'ä', 'è', 'é', 'ê', 'ë', 'ì', 'í', 'î', 'ï', 'ò', 'ó', 'ô', 'ö', 'ù', 'ú', 'û', 'ü':
+		return true
+	}
+	return false
+}
+
+// ReadingEase returns the Flesch reading ease of the counted text: 100 is
+// very easy to read, 0 very hard. It is 0 without sentences or words.
+func (c Counts) ReadingEase() float64 {
+	if c.Sentences == 0 || c.Words == 0 {
+		return 0
+	}
+	return 206.835 - 1.015*float64(c.Words)/float64(c.Sentences) - 84.6*float64(c.Syllables)/float64(c.Words)
+}
+
+// GradeLevel returns the Flesch-Kincaid grade level of the counted text,
+// the U.S. school grade needed to understand it. It is 0 without sentences or words.
+func (c Counts) GradeLevel() float64 {
+	if c.Sentences == 0 || c.Words == 0 {
+		return 0
+	}
+	return 0.39*float64(c.Words)/float64(c.Sentences) + 11.8*float64(c.Syllables)/float64(c.Words) - 15.59
+}
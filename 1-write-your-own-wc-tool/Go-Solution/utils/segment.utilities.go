@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// Word definitions accepted by ParseWordMode
+type WordMode string
+
+const (
+	// words are separated by white space, like POSIX wc
+	WordsPOSIX WordMode = "posix"
+	// words are found with the Unicode word boundaries (UAX #29), so that
+	// punctuation isn't part of a word and scripts without spaces are split too
+	WordsUnicode WordMode = "unicode"
+	// words are identifiers: runs of letters, digits & underscores which aren't only digits
+	WordsCode WordMode = "code"
+)
+
+// Throwing the custom error for an unknown word mode
+var ErrWordMode = errors.New("unknown word mode, expected posix, unicode or code")
+
+// ParseWordMode returns the WordMode for a name, ignoring case
+func ParseWordMode(name string) (WordMode, error) {
+	switch strings.ToLower(name) {
+	case "", "posix":
+		return WordsPOSIX, nil
+	case "unicode", "uax29":
+		return WordsUnicode, nil
+	case "code", "identifiers":
+		return WordsCode, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrWordMode, name)
+}
+
+// characterCounter counts something in a stream of decoded characters
+type characterCounter interface {
+	add(r rune)
+	// total returns the count so far, as if the stream ended after the last character
+	total() int64
+	// end is called once after the last character
+	end()
+}
+
+// newWordCounter returns the counter of a word mode, nil for WordsPOSIX which
+// the Counter handles itself. emit, when not nil, is called with every word.
+func newWordCounter(mode WordMode, emit func(word []byte)) characterCounter {
+	switch mode {
+	case WordsUnicode:
+		return &segmentCounter{
+			buffer: make([]byte, 0, segmentBufferSize+utf8.UTFMax),
+			state:  -1,
+			step: func(b []byte, state int) ([]byte, []byte, int) {
+				return uniseg.FirstWord(b, state)
+			},
+			counts: isWord,
+			emit:   emit,
+		}
+	case WordsCode:
+		return &identifierCounter{emit: emit}
+	}
+	return nil
+}
+
+// newGraphemeCounter returns a counter of grapheme clusters (user-perceived
+// characters, UAX #29), so that an emoji ZWJ sequence or a letter with
+// combining marks counts as one.
+func newGraphemeCounter() characterCounter {
+	return &segmentCounter{
+		buffer: make([]byte, 0, segmentBufferSize+utf8.UTFMax),
+		state:  -1,
+		step: func(b []byte, state int) ([]byte, []byte, int) {
+			cluster, rest, _, state := uniseg.Step(b, state)
+			return cluster, rest, state
+		},
+		counts: func([]byte) bool { return true },
+	}
+}
+
+// isWord reports whether a UAX #29 word segment is a word rather than spaces or punctuation
+func isWord(segment []byte) bool {
+	for _, r := range string(segment) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Size of the buffered text at which it is segmented
+const segmentBufferSize int = 4096
+
+// segmentCounter counts the segments of a stream of characters found by a uniseg step function
+type segmentCounter struct {
+	// UTF-8 text which hasn't been segmented yet, always starting on a segment boundary
+	buffer []byte
+	state  int
+	step   func(b []byte, state int) (segment []byte, rest []byte, newState int)
+	// counts reports whether a segment is counted
+	counts func(segment []byte) bool
+	// emit is called with every counted segment when not nil
+	emit  func(segment []byte)
+	count int64
+}
+
+func (s *segmentCounter) add(r rune) {
+	s.buffer = utf8.AppendRune(s.buffer, r)
+	if len(s.buffer) >= segmentBufferSize {
+		s.segment(false)
+	}
+}
+
+// segment counts the segments of the buffer. The last one may still be
+// extended by the next characters, so it is kept unless atEOF.
+func (s *segmentCounter) segment(atEOF bool) {
+	rest := s.buffer
+	for len(rest) > 0 {
+		segment, next, state := s.step(rest, s.state)
+		if len(next) == 0 && !atEOF {
+			break
+		}
+		if s.counts(segment) {
+			s.count++
+			if s.emit != nil {
+				s.emit(segment)
+			}
+		}
+		s.state = state
+		rest = next
+	}
+	s.buffer = append(s.buffer[:0], rest...)
+}
+
+func (s *segmentCounter) end() {
+	s.segment(true)
+}
+
+// total counts the segments still buffered too, without consuming them
+func (s *segmentCounter) total() int64 {
+	count, rest, state := s.count, s.buffer, s.state
+	for len(rest) > 0 {
+		var segment []byte
+		segment, rest, state = s.step(rest, state)
+		if s.counts(segment) {
+			count++
+		}
+	}
+	return count
+}
+
+// identifierCounter counts the identifiers of source code, like `parseArgs`
+// or `max_line_length`, leaving out numbers, operators & punctuation
+type identifierCounter struct {
+	inIdentifier bool
+	hasLetter    bool
+	count        int64
+	// emit is called with every identifier when not nil
+	emit       func(word []byte)
+	identifier []byte
+}
+
+func (i *identifierCounter) add(r rune) {
+	switch {
+	case unicode.IsDigit(r):
+		i.inIdentifier = true
+	case r == '_' || unicode.IsLetter(r) || i.inIdentifier && unicode.IsMark(r):
+		i.inIdentifier, i.hasLetter = true, true
+	default:
+		i.end()
+		return
+	}
+	if i.emit != nil {
+		i.identifier = utf8.AppendRune(i.identifier, r)
+	}
+}
+
+func (i *identifierCounter) end() {
+	if i.inIdentifier && i.hasLetter {
+		i.count++
+		if i.emit != nil {
+			i.emit(i.identifier)
+		}
+	}
+	i.inIdentifier, i.hasLetter, i.identifier = false, false, i.identifier[:0]
+}
+
+func (i *identifierCounter) total() int64 {
+	if i.inIdentifier && i.hasLetter {
+		return i.count + 1
+	}
+	return i.count
+}
@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// Language is the comment syntax of a programming language, used to tell
+// code, comment & blank lines apart
+type Language struct {
+	Name string
+	// file extensions with their dot, like ".go", and whole file names, like "Makefile"
+	Extensions []string
+	Filenames  []string
+	// interpreters named by a #! line, like "python" for `#!/usr/bin/env python3`
+	Interpreters []string
+
+	LineComments  []string
+	BlockComments [][2]string
+	// block comments can be nested, like /* /* */ */ in Rust
+	NestedComments bool
+	// string delimiters, comment markers inside a string are code. Longer
+	// delimiters go first, like """ before ".
+	Quotes []string
+	// delimiters of Quotes whose strings can span lines, like Go raw strings
+	MultilineQuotes []string
+	// delimiters of Quotes whose strings have no escapes, like Go raw strings
+	RawQuotes []string
+}
+
+var (
+	cStyle       = [][2]string{{"/*", "*/"}}
+	cQuotes      = []string{`"`, `'`}
+	tripleQuotes = []string{`"""`, `"`, `'`}
+)
+
+// Languages is the table DetectLanguage looks languages up in, the first match wins.
+// More can be added with RegisterLanguage.
+var Languages = []*Language{
+	{Name: "Go", Extensions: []string{".go"}, LineComments: []string{"//"}, BlockComments: cStyle, Quotes: []string{`"`, `'`, "`"}, MultilineQuotes: []string{"`"}, RawQuotes: []string{"`"}},
+	{Name: "C", Extensions: []string{".c", ".h"}, LineComments: []string{"//"}, BlockComments: cStyle, Quotes: cQuotes},
+	{Name: "C++", Extensions: []string{".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx"}, LineComments: []string{"//"}, BlockComments: cStyle, Quotes: cQuotes},
+	{Name: "C#", Extensions: []string{".cs"}, LineComments: []string{"//"}, BlockComments: cStyle, Quotes: cQuotes},
+	{Name: "Java", Extensions: []string{".java"}, LineComments: []string{"//"}, BlockComments: cStyle, Quotes: cQuotes},
+	{Name: "Kotlin", Extensions: []string{".kt", ".kts"}, LineComments: []string{"//"}, BlockComments: cStyle, NestedComments: true, Quotes: tripleQuotes, MultilineQuotes: []string{`"""`}, RawQuotes: []string{`"""`}},
+	{Name: "Scala", Extensions: []string{".scala"}, LineComments: []string{"//"}, BlockComments: cStyle, NestedComments: true, Quotes: tripleQuotes, MultilineQuotes: []string{`"""`}, RawQuotes: []string{`"""`}},
+	{Name: "Swift", Extensions: []string{".swift"}, LineComments: []string{"//"}, BlockComments: cStyle, NestedComments: true, Quotes: []string{`"""`, `"`}, MultilineQuotes: []string{`"""`}},
+	{Name: "Rust", Extensions: []string{".rs"}, LineComments: []string{"//"}, BlockComments: cStyle, NestedComments: true, Quotes: []string{`"`}, MultilineQuotes: []string{`"`}},
+	{Name: "JavaScript", Extensions: []string{".js", ".mjs", ".cjs", ".jsx"}, Interpreters: []string{"node"}, LineComments: []string{"//"}, BlockComments: cStyle, Quotes: []string{`"`, `'`, "`"}, MultilineQuotes: []string{"`"}},
+	{Name: "TypeScript", Extensions: []string{".ts", ".tsx"}, LineComments: []string{"//"}, BlockComments: cStyle, Quotes: []string{`"`, `'`, "`"}, MultilineQuotes: []string{"`"}},
+	{Name: "CSS", Extensions: []string{".css"}, BlockComments: cStyle, Quotes: cQuotes},
+	{Name: "PHP", Extensions: []string{".php"}, Interpreters: []string{"php"}, LineComments: []string{"//", "#"}, BlockComments: cStyle, Quotes: cQuotes},
+	{Name: "Python", Extensions: []string{".py", ".pyw"}, Interpreters: []string{"python"}, LineComments: []string{"#"}, Quotes: []string{`"""`, `'''`, `"`, `'`}, MultilineQuotes: []string{`"""`, `'''`}},
+	{Name: "Ruby", Extensions: []string{".rb"}, Filenames: []string{"Gemfile", "Rakefile"}, Interpreters: []string{"ruby"}, LineComments: []string{"#"}, Quotes: cQuotes},
+	{Name: "Perl", Extensions: []string{".pl", ".pm"}, Interpreters: []string{"perl"}, LineComments: []string{"#"}, Quotes: cQuotes},
+	{Name: "Shell", Extensions: []string{".sh", ".bash", ".zsh"}, Interpreters: []string{"sh", "bash", "zsh", "dash", "ksh"}, LineComments: []string{"#"}, Quotes: cQuotes},
+	{Name: "R", Extensions: []string{".r"}, Interpreters: []string{"Rscript"}, LineComments: []string{"#"}, Quotes: cQuotes},
+	{Name: "Lua", Extensions: []string{".lua"}, Interpreters: []string{"lua"}, LineComments: []string{"--"}, BlockComments: [][2]string{{"--[[", "]]"}}, Quotes: cQuotes},
+	{Name: "Haskell", Extensions: []string{".hs"}, LineComments: []string{"--"}, BlockComments: [][2]string{{"{-", "-}"}}, NestedComments: true, Quotes: []string{`"`}},
+	{Name: "SQL", Extensions: []string{".sql"}, LineComments: []string{"--"}, BlockComments: cStyle, Quotes: []string{`'`}},
+	{Name: "HTML", Extensions: []string{".html", ".htm"}, BlockComments: [][2]string{{"<!--", "-->"}}},
+	{Name: "XML", Extensions: []string{".xml", ".svg"}, BlockComments: [][2]string{{"<!--", "-->"}}},
+	{Name: "YAML", Extensions: []string{".yaml", ".yml"}, LineComments: []string{"#"}, Quotes: cQuotes},
+	{Name: "TOML", Extensions: []string{".toml"}, LineComments: []string{"#"}, Quotes: cQuotes},
+	{Name: "Makefile", Extensions: []string{".mk"}, Filenames: []string{"Makefile", "makefile", "GNUmakefile"}, LineComments: []string{"#"}},
+	{Name: "Dockerfile", Filenames: []string{"Dockerfile"}, LineComments: []string{"#"}},
+	{Name: "Markdown", Extensions: []string{".md", ".markdown"}, BlockComments: [][2]string{{"<!--", "-->"}}},
+	{Name: "Text", Extensions: []string{".txt"}},
+}
+
+// RegisterLanguage adds a language to Languages, before the others so it overrides them
+func RegisterLanguage(language *Language) {
+	Languages = append([]*Language{language}, Languages...)
+}
+
+// DetectLanguage returns the language of a file from its name or extension, nil when unknown
+func DetectLanguage(filename string) *Language {
+	base := filepath.Base(filename)
+	extension := strings.ToLower(filepath.Ext(base))
+	for _, language := range Languages {
+		for _, name := range language.Filenames {
+			if base == name {
+				return language
+			}
+		}
+	}
+	for _, language := range Languages {
+		for _, ext := range language.Extensions {
+			if extension == ext {
+				return language
+			}
+		}
+	}
+	return nil
+}
+
+// DetectShebang returns the language of the interpreter named by a #! first line, nil when unknown
+func DetectShebang(firstLine []byte) *Language {
+	if !bytes.HasPrefix(firstLine, []byte("#!")) {
+		return nil
+	}
+	fields := strings.Fields(string(firstLine[2:]))
+	if len(fields) == 0 {
+		return nil
+	}
+	interpreter := filepath.Base(fields[0])
+	// `#!/usr/bin/env -S python3 -u` names the interpreter after env's own flags
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	// python3.12 is python
+	versionless := strings.TrimRight(interpreter, "0123456789.")
+	for _, language := range Languages {
+		for _, name := range language.Interpreters {
+			if interpreter == name || versionless == name {
+				return language
+			}
+		}
+	}
+	return nil
+}
+
+// LanguageCounts holds the lines of every kind of the files of a language
+type LanguageCounts struct {
+	Files   int64
+	Code    int64
+	Comment int64
+	Blank   int64
+}
+
+// slocCounter tells the code, comment & blank lines of a source file apart
+type slocCounter struct {
+	language *Language
+	started  bool
+	// nesting depth of the block comment the line starts in, with the index of its delimiters
+	depth int
+	block int
+	// delimiter of the string the line starts in, for the strings spanning lines
+	quote string
+
+	code    int64
+	comment int64
+	blank   int64
+}
+
+// line classifies a line without its newline. A line with any code is a
+// code line, else a line with a comment is a comment line.
+func (s *slocCounter) line(text []byte) {
+	if !s.started {
+		s.started = true
+		if s.language == nil {
+			s.language = DetectShebang(text)
+		}
+	}
+	if s.language == nil {
+		return
+	}
+
+	hasCode, hasComment := s.classify(text)
+	// a string that can't span lines ends with its line, even when it isn't closed
+	if s.quote != "" && !contains(s.language.MultilineQuotes, s.quote) {
+		s.quote = ""
+	}
+	switch {
+	case hasCode:
+		s.code++
+	case hasComment:
+		s.comment++
+	default:
+		s.blank++
+	}
+}
+
+// classify reports whether a line has code & comments, the block comment &
+// the string still open at its end carry over to the next line
+func (s *slocCounter) classify(line []byte) (hasCode bool, hasComment bool) {
+	language := s.language
+
+	for i := 0; i < len(line); {
+		rest := line[i:]
+
+		switch {
+		case s.depth > 0:
+			delimiters := language.BlockComments[s.block]
+			switch {
+			case language.NestedComments && bytes.HasPrefix(rest, []byte(delimiters[0])):
+				s.depth++
+				i += len(delimiters[0])
+			case bytes.HasPrefix(rest, []byte(delimiters[1])):
+				s.depth--
+				i += len(delimiters[1])
+			default:
+				i++
+			}
+			if !isBlank(rest[0]) {
+				hasComment = true
+			}
+			continue
+
+		case s.quote != "":
+			// the text of a string is code, on every line it spans
+			if !isBlank(rest[0]) {
+				hasCode = true
+			}
+			if rest[0] == '\\' && !contains(language.RawQuotes, s.quote) {
+				i += 2
+			} else if bytes.HasPrefix(rest, []byte(s.quote)) {
+				i += len(s.quote)
+				s.quote = ""
+			} else {
+				i++
+			}
+			continue
+
+		case isBlank(rest[0]):
+			i++
+			continue
+		}
+
+		// block comments first, "--[[" starts a block but "--" a line comment in Lua
+		if block := prefixIndex(rest, language.BlockComments); block >= 0 {
+			s.depth, s.block = 1, block
+			i += len(language.BlockComments[block][0])
+			hasComment = true
+			continue
+		}
+		for _, marker := range language.LineComments {
+			if bytes.HasPrefix(rest, []byte(marker)) {
+				return hasCode, true
+			}
+		}
+
+		hasCode = true
+		i++
+		for _, q := range language.Quotes {
+			if bytes.HasPrefix(rest, []byte(q)) {
+				s.quote = q
+				i += len(q) - 1
+				break
+			}
+		}
+	}
+	return hasCode, hasComment
+}
+
+// prefixIndex returns the index of the block comment starting p, -1 for none
+func prefixIndex(p []byte, blocks [][2]string) int {
+	for i, delimiters := range blocks {
+		if bytes.HasPrefix(p, []byte(delimiters[0])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// contains reports whether delimiter is one of delimiters
+func contains(delimiters []string, delimiter string) bool {
+	for _, d := range delimiters {
+		if d == delimiter {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\f' || b == '\v'
+}
+
+// counts returns the lines of every kind, by language for the totals
+func (s *slocCounter) counts(counts *Counts) {
+	counts.CodeLines, counts.CommentLines, counts.BlankLines = s.code, s.comment, s.blank
+	if s.language != nil {
+		counts.Languages = map[string]LanguageCounts{
+			s.language.Name: {Files: 1, Code: s.code, Comment: s.comment, Blank: s.blank},
+		}
+	}
+}
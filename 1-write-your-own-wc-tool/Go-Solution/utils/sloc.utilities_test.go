@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlocCounter(t *testing.T) {
+	tests := []struct {
+		name                 string
+		filename             string
+		input                string
+		code, comment, blank int64
+	}{
+		{"line & block comments", "a.go", "// doc\npackage a\n\n/* one\ntwo */\nvar x = 1 // trailing\n", 2, 3, 1},
+		{"code after a block comment", "a.c", "/* a */ int x;\n/* b\n*/ int y;\n", 2, 1, 0},
+		{"nested comments", "a.rs", "/* outer /* inner */\nstill a comment */\nfn main() {}\n", 1, 2, 0},
+		{"comments can't nest in C", "a.c", "/* outer /* inner */\nint x; */\n", 1, 1, 0},
+		{"comment markers in strings", "a.go", "s := \"// not a comment\"\nt := '/' /* c */\nu := \"/* nor this\"\n", 3, 0, 0},
+		{"escaped quote", "a.js", "s = \"a\\\" // b\"\n// c\n", 1, 1, 0},
+		{"unclosed string ends with its line", "a.c", "char *s = \"abc\n// comment\n", 1, 1, 0},
+		{"go raw string", "a.go", "var s = `\n// x\n/* y\n`\nfunc f() {}\n", 5, 0, 0},
+		{"raw string has no escapes", "a.go", "var s = `C:\\`\n// comment\n", 1, 1, 0},
+		{"blank line in a raw string", "a.go", "var s = `a\n\nb`\n", 2, 0, 1},
+		{"template literal", "a.ts", "const s = `\n  /* not a comment\n`;\n// comment\n", 3, 1, 0},
+		{"python triple quotes", "a.py", "s = \"\"\"\n# not a comment\n\"\"\"\n# comment\nt = 'a' # c\n", 4, 1, 0},
+		{"lua block comment", "a.lua", "--[[ block\nstill ]]\n-- line\nprint(1)\n", 1, 3, 0},
+		{"shebang", "script", "#!/usr/bin/env python3\n# comment\n\nprint(1)\n", 1, 2, 1},
+		{"unknown language", "a.unknown", "code\n// comment\n", 0, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := slocCounter{language: DetectLanguage(test.filename)}
+			for _, line := range strings.SplitAfter(test.input, "\n") {
+				if line != "" {
+					s.line([]byte(strings.TrimSuffix(line, "\n")))
+				}
+			}
+			if s.code != test.code || s.comment != test.comment || s.blank != test.blank {
+				t.Errorf("code, comment, blank = %d %d %d, want %d %d %d", s.code, s.comment, s.blank, test.code, test.comment, test.blank)
+			}
+		})
+	}
+}
+
+func TestDetectShebang(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"#!/bin/bash", "Shell"},
+		{"#!/bin/sh -e", "Shell"},
+		{"#!/usr/bin/env python3", "Python"},
+		{"#!/usr/bin/python3.12", "Python"},
+		{"#!/usr/bin/env -S python3 -u", "Python"},
+		{"#! /usr/bin/env node", "JavaScript"},
+		{"#!/usr/bin/env", ""},
+		{"#!/usr/bin/unknown", ""},
+		{"# not a shebang", ""},
+	}
+	for _, test := range tests {
+		got := ""
+		if language := DetectShebang([]byte(test.line)); language != nil {
+			got = language.Name
+		}
+		if got != test.want {
+			t.Errorf("DetectShebang(%q) = %q, want %q", test.line, got, test.want)
+		}
+	}
+}
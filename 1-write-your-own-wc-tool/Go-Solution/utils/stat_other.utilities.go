@@ -0,0 +1,10 @@
+//go:build !unix
+
+package utils
+
+import "io/fs"
+
+// ownership isn't known outside of Unix systems
+func ownership(fileInfo fs.FileInfo) (owner string, group string, inode uint64, links uint64) {
+	return "", "", 0, 0
+}
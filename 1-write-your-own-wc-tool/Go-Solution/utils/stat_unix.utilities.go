@@ -0,0 +1,28 @@
+//go:build unix
+
+package utils
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// ownership returns the owner & group names, the inode & the number of hard links of a file
+func ownership(fileInfo fs.FileInfo) (owner string, group string, inode uint64, links uint64) {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", 0, 0
+	}
+
+	owner = strconv.FormatUint(uint64(stat.Uid), 10)
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group = strconv.FormatUint(uint64(stat.Gid), 10)
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	return owner, group, uint64(stat.Ino), uint64(stat.Nlink)
+}
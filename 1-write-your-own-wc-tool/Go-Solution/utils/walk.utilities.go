@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Number of leading bytes looked at to decide whether a file is binary, like git does
+const BinarySniffSize int = 8000
+
+// WalkOptions select the files Walk returns
+type WalkOptions struct {
+	// glob patterns a file has to match (any of them) to be counted, all files when empty
+	Include []string
+	// glob patterns of files & directories to leave out
+	Exclude []string
+	// honour the .gitignore files found while walking, and skip the .git directories
+	GitIgnore bool
+	// leave out files which look binary
+	SkipBinary bool
+}
+
+// Walk returns the regular files below root in lexical order, filtered by
+// the options. Directories which can't be read are skipped and reported in
+// the returned errors, the walk carries on with the rest of the tree.
+func Walk(root string, options WalkOptions) ([]string, []error) {
+	var files []string
+	var errs []error
+	var rules []ignoreRule
+
+	err := filepath.WalkDir(root, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error walking directory: %w", err))
+			if entry != nil && entry.IsDir() && filename != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filename)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if filename == root {
+				rel = ""
+			} else if skip(rel, true, options, rules) {
+				return fs.SkipDir
+			}
+			if options.GitIgnore {
+				dirRules, err := readIgnoreFile(filepath.Join(filename, ".gitignore"), rel)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				rules = append(rules, dirRules...)
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() || skip(rel, false, options, rules) {
+			return nil
+		}
+		if len(options.Include) > 0 && !matchAny(options.Include, rel) {
+			return nil
+		}
+		if options.SkipBinary {
+			binary, err := IsBinaryFile(filename)
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			if binary {
+				return nil
+			}
+		}
+
+		files = append(files, filename)
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("error walking directory: %w", err))
+	}
+
+	return files, errs
+}
+
+// skip reports whether a file or directory is excluded or ignored
+func skip(rel string, isDir bool, options WalkOptions, rules []ignoreRule) bool {
+	if matchAny(options.Exclude, rel) {
+		return true
+	}
+	if !options.GitIgnore {
+		return false
+	}
+	if isDir && path.Base(rel) == ".git" {
+		return true
+	}
+
+	// the last matching rule wins, so a later "!pattern" can bring a file back
+	ignored := false
+	for _, rule := range rules {
+		if rule.match(rel, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matchAny reports whether the slash separated path matches one of the
+// patterns. Patterns without a slash are matched against the base name only.
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+		} else if matchGlob(strings.TrimPrefix(pattern, "/"), rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash separated path against a pattern where "**"
+// stands for any number of directories
+func matchGlob(pattern string, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// try every number of directories for the "**"
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignoreRule is a single line of a .gitignore file
+type ignoreRule struct {
+	pattern  string
+	base     string // directory of the .gitignore file, relative to the walked root
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// match reports whether the rule applies to the path relative to the walked root
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = rel[len(r.base)+1:]
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.pattern, path.Base(rel))
+		return ok
+	}
+	return matchGlob(r.pattern, rel)
+}
+
+// readIgnoreFile parses a .gitignore file, a missing one has no rules
+func readIgnoreFile(filename string, base string) ([]ignoreRule, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	// read line by line without a limit on their length, the last one may not end with a newline
+	var rules []ignoreRule
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return rules, fmt.Errorf("error reading %s: %w", filename, err)
+		}
+		if err == io.EOF && line == "" {
+			break
+		}
+
+		line = strings.TrimRight(line, " \t\r\n")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, "\\")
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		// a slash anywhere but at the end ties the pattern to the .gitignore directory
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// IsBinaryFile reports whether the file has a NUL byte in its first BinarySniffSize bytes
+func IsBinaryFile(filename string) (bool, error) {
+	header, err := readHeader(filename, BinarySniffSize)
+	if err != nil {
+		return false, err
+	}
+	return isBinary(header), nil
+}
+
+// isBinary reports whether the first bytes of a file have a NUL byte
+func isBinary(header []byte) bool {
+	return bytes.IndexByte(header, 0) >= 0
+}
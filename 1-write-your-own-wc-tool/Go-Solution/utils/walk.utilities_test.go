@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree creates the files under dir, with their contents
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gitignore":          "*.log\n!keep.log\nbuild/\n/root-only.txt\n# comment\n\n",
+		"a.go":                "package a\n",
+		"a.log":               "ignored\n",
+		"keep.log":            "kept by the negation\n",
+		"root-only.txt":       "anchored to the root\n",
+		"bin.dat":             "binary\x00data",
+		"build/out.txt":       "in an ignored directory\n",
+		"src/b.go":            "package b\n",
+		"src/root-only.txt":   "the anchored pattern doesn't apply here\n",
+		"src/.gitignore":      "generated.go\n",
+		"src/generated.go":    "ignored by the nested .gitignore\n",
+		"src/vendor/c.go":     "package c\n",
+		"other/generated.go":  "the nested rules don't apply here\n",
+		".git/HEAD":           "ref: refs/heads/main\n",
+		"docs/guide/intro.md": "# Intro\n",
+	})
+
+	tests := []struct {
+		name    string
+		options WalkOptions
+		want    []string
+	}{
+		{"everything", WalkOptions{}, []string{
+			".git/HEAD", ".gitignore", "a.go", "a.log", "bin.dat", "build/out.txt", "docs/guide/intro.md", "keep.log",
+			"other/generated.go", "root-only.txt", "src/.gitignore", "src/b.go", "src/generated.go", "src/root-only.txt", "src/vendor/c.go",
+		}},
+		{"gitignore", WalkOptions{GitIgnore: true}, []string{
+			".gitignore", "a.go", "bin.dat", "docs/guide/intro.md", "keep.log",
+			"other/generated.go", "src/.gitignore", "src/b.go", "src/root-only.txt", "src/vendor/c.go",
+		}},
+		{"include base name", WalkOptions{Include: []string{"*.go"}, GitIgnore: true}, []string{
+			"a.go", "other/generated.go", "src/b.go", "src/vendor/c.go",
+		}},
+		{"exclude directory", WalkOptions{Include: []string{"*.go"}, Exclude: []string{"vendor"}}, []string{
+			"a.go", "other/generated.go", "src/b.go", "src/generated.go",
+		}},
+		{"include with **", WalkOptions{Include: []string{"docs/**/*.md"}}, []string{"docs/guide/intro.md"}},
+		{"skip binary", WalkOptions{Include: []string{"*.dat", "*.go"}, SkipBinary: true, GitIgnore: true}, []string{
+			"a.go", "other/generated.go", "src/b.go", "src/vendor/c.go",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files, errs := Walk(dir, test.options)
+			if len(errs) > 0 {
+				t.Fatalf("Walk errors: %v", errs)
+			}
+			got := make([]string, len(files))
+			for i, filename := range files {
+				rel, err := filepath.Rel(dir, filename)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[i] = filepath.ToSlash(rel)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Walk = %q\nwant %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"a/*.go", "a/b.go", true},
+		{"a/*.go", "a/b/c.go", false},
+		{"a/**/*.go", "a/c.go", true},
+		{"a/**/*.go", "a/b/c/d.go", true},
+		{"**/test", "x/y/test", true},
+		{"**/test", "x/y/tests", false},
+	}
+	for _, test := range tests {
+		if got := matchGlob(test.pattern, test.name); got != test.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
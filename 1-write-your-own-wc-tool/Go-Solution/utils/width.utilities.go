@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// Distance between two tab stops, like GNU wc -L
+const TabWidth int64 = 8
+
+// nextTabStop returns the column a tab at column moves to
+func nextTabStop(column int64) int64 {
+	return (column/TabWidth + 1) * TabWidth
+}
+
+// runeWidth returns the number of columns a character takes on a terminal,
+// like wcwidth(3): 2 for East Asian wide & fullwidth characters, 0 for
+// combining marks, format characters & everything which isn't printable.
+func runeWidth(r rune) int64 {
+	switch {
+	case r < utf8.RuneSelf:
+		if r >= 0x20 && r < 0x7F {
+			return 1
+		}
+		return 0
+	case !unicode.IsGraphic(r):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1160 && r <= 0x11FF:
+		// Hangul vowels & final consonants join the preceding syllable
+		return 0
+	}
+
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
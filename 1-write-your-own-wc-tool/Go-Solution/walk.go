@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+)
+
+// subtotals adds up the counts of the files found under every walked directory
+type subtotals struct {
+	// whether every expanded input was found by walking a directory, the same
+	// file may be found under several of the directories given
+	walked []bool
+	// the directories given on the command line which were walked
+	roots  []string
+	counts map[string]*utils.Counts
+}
+
+// expandInputs replaces the directories among files by the files below them
+// when walking is enabled. Directories which can't be walked are reported on
+// stderr and make the returned status 1.
+func expandInputs(files []string, opts options) ([]string, *subtotals, int) {
+	sums := &subtotals{counts: map[string]*utils.Counts{}}
+	if !opts.recursive {
+		return files, sums, 0
+	}
+
+	walkOptions := utils.WalkOptions{
+		Include:    opts.include,
+		Exclude:    opts.exclude,
+		GitIgnore:  opts.gitignore,
+		SkipBinary: opts.skipBinary,
+	}
+
+	status := 0
+	expanded := make([]string, 0, len(files))
+	for _, filename := range files {
+		fileInfo, err := os.Stat(filename)
+		if filename == Stdin || err != nil || !fileInfo.IsDir() {
+			expanded = append(expanded, filename)
+			sums.walked = append(sums.walked, false)
+			continue
+		}
+
+		walked, errs := utils.Walk(filename, walkOptions)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "wc: %s\n", err)
+			status = 1
+		}
+		for range walked {
+			sums.walked = append(sums.walked, true)
+		}
+		sums.roots = append(sums.roots, filepath.Clean(filename))
+		expanded = append(expanded, walked...)
+	}
+	return expanded, sums, status
+}
+
+// add counts the input at index i of the expanded inputs, when it was walked,
+// in the subtotal of every directory above it within a walked directory. So
+// every subtotal adds up the rows below it, even when the walked directories
+// overlap and a file is counted more than once.
+func (s *subtotals) add(i int, filename string, counts utils.Counts) {
+	if i >= len(s.walked) || !s.walked[i] {
+		return
+	}
+	for dir := filepath.Dir(filename); s.inRoot(dir); dir = filepath.Dir(dir) {
+		if s.counts[dir] == nil {
+			s.counts[dir] = &utils.Counts{}
+		}
+		s.counts[dir].Add(counts)
+
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+}
+
+// inRoot reports whether dir is one of the walked directories or below one
+func (s *subtotals) inRoot(dir string) bool {
+	for _, root := range s.roots {
+		rel, err := filepath.Rel(root, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// report writes the subtotals, parent directories before their children
+func (s *subtotals) report(output reporter) {
+	dirs := make([]string, 0, len(s.counts))
+	for dir := range s.counts {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		output.subtotal(dir+string(filepath.Separator), *s.counts[dir])
+	}
+}
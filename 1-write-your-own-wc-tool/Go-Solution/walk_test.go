@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+)
+
+// every subtotal adds up the rows below it, even when the walked directories overlap
+func TestSubtotalsOverlappingRoots(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"top.txt", "src/a.txt", "src/sub/b.txt"} {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte("line\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src, sub := filepath.Join(dir, "src"), filepath.Join(dir, "src", "sub")
+
+	tests := []struct {
+		name  string
+		roots []string
+		want  map[string]int64
+	}{
+		{"parent first", []string{dir, src}, map[string]int64{dir: 5, src: 4, sub: 2}},
+		{"child after parent", []string{src, sub}, map[string]int64{src: 3, sub: 2}},
+		{"child first", []string{sub, src}, map[string]int64{src: 3, sub: 2}},
+		{"same root twice", []string{src, src}, map[string]int64{src: 4, sub: 2}},
+		{"file and root", []string{filepath.Join(dir, "top.txt"), sub}, map[string]int64{sub: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files, sums, status := expandInputs(test.roots, options{recursive: true})
+			if status != 0 {
+				t.Fatalf("expandInputs status = %d", status)
+			}
+
+			// every row is a single line, the total is the number of rows
+			for i, filename := range files {
+				sums.add(i, filename, utils.Counts{Lines: 1})
+			}
+			got := map[string]int64{}
+			for dir, counts := range sums.counts {
+				got[dir] = counts.Lines
+			}
+			if len(got) != len(test.want) {
+				t.Errorf("subtotals = %v, want %v", got, test.want)
+			}
+			for dir, lines := range test.want {
+				if got[dir] != lines {
+					t.Errorf("subtotal of %s = %d, want %d", dir, got[dir], lines)
+				}
+			}
+		})
+	}
+}
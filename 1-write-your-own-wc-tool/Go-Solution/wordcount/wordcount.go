@@ -0,0 +1,155 @@
+// Package wordcount counts the lines, words, characters & bytes of text in-process,
+// the same way the wc command does.
+package wordcount
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+)
+
+// Counts holds every metric gathered from an input, see utils.Counts
+type Counts = utils.Counts
+
+// Default number of bytes read between two calls of Options.Progress
+const DefaultProgressInterval int64 = 1024 * 1024
+
+// Options select what is counted and how, the zero value counts UTF-8 text
+// like POSIX wc
+type Options struct {
+	utils.CountOptions
+
+	// Jobs is the number of byte ranges a large regular file is split into by
+	// CountFile, which are counted concurrently. 0 or 1 counts it sequentially.
+	Jobs int
+
+	// Progress, when not nil, is called with the number of bytes of the input
+	// counted so far every ProgressInterval bytes (DefaultProgressInterval when
+	// 0) and once at the end. The bytes only probed or skipped by seeking aren't
+	// part of it. Calls never overlap, even while counting concurrently.
+	Progress         func(bytesRead int64)
+	ProgressInterval int64
+}
+
+// Count reads r until EOF and returns its counts. It stops early with an
+// error wrapping ctx.Err() once ctx is done.
+func Count(ctx context.Context, r io.Reader, options Options) (Counts, error) {
+	progress := newProgress(options)
+	counts, err := utils.CountReader(&contextReader{ctx: ctx, r: r}, progress.countOptions(options))
+	progress.done()
+	return counts, err
+}
+
+// CountFile opens the named file and returns its counts, see Count. A large
+// regular file is counted in options.Jobs concurrent byte ranges, and the
+// Range of a regular file is sought rather than read.
+func CountFile(ctx context.Context, filename string, options Options) (Counts, error) {
+	// initiate file-handle to read from
+	file, err := os.Open(filename)
+	if err != nil {
+		return Counts{}, fmt.Errorf("error opening file: %w", err)
+	}
+
+	// make sure to close the file-handle upon return
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return Counts{}, fmt.Errorf("error reading file information: %w", err)
+	}
+	// the range of a regular file is sought even when it is counted sequentially
+	if !fileInfo.Mode().IsRegular() || (options.Jobs <= 1 || fileInfo.Size() < 2*utils.MinChunkSize) && options.Range.IsZero() {
+		return Count(ctx, file, options)
+	}
+
+	progress := newProgress(options)
+	counts, err := utils.CountChunks(&contextReaderAt{ctx: ctx, r: file}, fileInfo.Size(), options.Jobs, progress.countOptions(options))
+	progress.done()
+	return counts, err
+}
+
+// progress calls Options.Progress, from any goroutine
+type progress struct {
+	callback func(bytesRead int64)
+	interval int64
+
+	mutex      sync.Mutex
+	bytesRead  int64
+	lastCalled int64
+}
+
+func newProgress(options Options) *progress {
+	interval := options.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	return &progress{callback: options.Progress, interval: interval}
+}
+
+// countOptions returns the options of the counters, reporting the bytes they
+// read: the probes of the input aren't part of the progress
+func (p *progress) countOptions(options Options) utils.CountOptions {
+	countOptions := options.CountOptions
+	if p.callback != nil {
+		onRead := countOptions.OnRead
+		countOptions.OnRead = func(n int) {
+			if onRead != nil {
+				onRead(n)
+			}
+			p.add(n)
+		}
+	}
+	return countOptions
+}
+
+func (p *progress) add(n int) {
+	if p.callback == nil || n == 0 {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.bytesRead += int64(n)
+	if p.bytesRead-p.lastCalled >= p.interval {
+		p.lastCalled = p.bytesRead
+		p.callback(p.bytesRead)
+	}
+}
+
+func (p *progress) done() {
+	if p.callback == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.callback(p.bytesRead)
+}
+
+// contextReader stops reading once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// contextReaderAt stops reading once its context is done
+type contextReaderAt struct {
+	ctx context.Context
+	r   io.ReaderAt
+}
+
+func (c *contextReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.ReadAt(p, off)
+}
@@ -0,0 +1,121 @@
+package wordcount
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abhishekpatel946/1-write-your-own-wc-tool/utils"
+)
+
+// writeFile writes contents to a file of a temporary directory, returning its name
+func writeFile(t *testing.T, name string, contents []byte) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, contents, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// large is split into chunks by CountFile
+var large = bytes.Repeat([]byte("one two three\nfour five\n"), int(2*utils.MinChunkSize/24+1000))
+
+func TestCount(t *testing.T) {
+	counts, err := Count(context.Background(), strings.NewReader("one two\nthree\n"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.Lines != 2 || counts.Words != 3 || counts.Bytes != 14 {
+		t.Errorf("Count = %d %d %d, want 2 3 14", counts.Lines, counts.Words, counts.Bytes)
+	}
+}
+
+func TestCountCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Count(ctx, strings.NewReader("one two\n"), Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Count = %v, want an error wrapping context.Canceled", err)
+	}
+
+	filename := writeFile(t, "large.txt", large)
+	if _, err := CountFile(ctx, filename, Options{Jobs: 4}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CountFile = %v, want an error wrapping context.Canceled", err)
+	}
+}
+
+// counting stops at the next read once the context is cancelled
+func TestCountCancelledWhileCounting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int
+	options := Options{ProgressInterval: 1, Progress: func(int64) {
+		calls++
+		cancel()
+	}}
+	_, err := Count(ctx, &oneByteReader{strings.NewReader("one two three\n")}, options)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Count = %v, want an error wrapping context.Canceled", err)
+	}
+	// once when cancelling, once at the end
+	if calls != 2 {
+		t.Errorf("Progress called %d times, want 2", calls)
+	}
+}
+
+// oneByteReader reads a single byte at a time
+type oneByteReader struct {
+	r *strings.Reader
+}
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	return o.r.Read(p[:min(len(p), 1)])
+}
+
+func TestProgress(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(large)
+	writer.Close()
+
+	plain := writeFile(t, "large.txt", large)
+	gzipped := writeFile(t, "large.txt.gz", compressed.Bytes())
+
+	tests := []struct {
+		name     string
+		filename string
+		options  Options
+		want     int64
+	}{
+		{"sequential", plain, Options{}, int64(len(large))},
+		{"chunks", plain, Options{Jobs: 4}, int64(len(large))},
+		{"sought range", plain, Options{Jobs: 4, CountOptions: utils.CountOptions{Range: utils.Range{Offset: 1000, Length: 5000}}}, 5000},
+		{"sought lines", plain, Options{Jobs: 4, CountOptions: utils.CountOptions{Range: utils.Range{FromLine: 3, ToLine: 4}}}, 24},
+		{"compressed chunks", gzipped, Options{Jobs: 4}, int64(compressed.Len())},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reported []int64
+			options := test.options
+			options.ProgressInterval = 64 * 1024
+			options.Progress = func(bytesRead int64) { reported = append(reported, bytesRead) }
+			if _, err := CountFile(context.Background(), test.filename, options); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(reported) == 0 || reported[len(reported)-1] != test.want {
+				t.Fatalf("Progress = %v, want %d at the end", reported, test.want)
+			}
+			for i := 1; i < len(reported); i++ {
+				if reported[i] < reported[i-1] {
+					t.Fatalf("Progress went back from %d to %d", reported[i-1], reported[i])
+				}
+			}
+		})
+	}
+}